@@ -0,0 +1,86 @@
+package ah
+
+import (
+	"context"
+	"sync"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+)
+
+// cachedList keeps the result of a list call for the rest of the run. The lock is held while
+// loading, so concurrent resources wait for a single API call instead of repeating it.
+type cachedList[T any] struct {
+	mu     sync.Mutex
+	items  []T
+	loaded bool
+}
+
+func (c *cachedList[T]) get(load func() ([]T, error)) ([]T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loaded {
+		items, err := load()
+		if err != nil {
+			return nil, err
+		}
+		c.items = items
+		c.loaded = true
+	}
+	return c.items, nil
+}
+
+func (c *cachedList[T]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = nil
+	c.loaded = false
+}
+
+// lookupCache holds reference data that resources resolve slugs, IDs and fingerprints against.
+// Returned slices are shared and must not be modified.
+type lookupCache struct {
+	datacenters   cachedList[ah.Datacenter]
+	instancePlans cachedList[ah.InstancePlan]
+	volumePlans   cachedList[ah.VolumePlan]
+	images        cachedList[ah.Image]
+	k8sVersions   cachedList[string]
+	sshKeys       cachedList[ah.SSHKey]
+}
+
+func (c *CombinedConfig) datacenters(ctx context.Context) ([]ah.Datacenter, error) {
+	return c.cache.datacenters.get(func() ([]ah.Datacenter, error) {
+		return c.client.Datacenters.List(ctx, nil)
+	})
+}
+
+func (c *CombinedConfig) instancePlans(ctx context.Context) ([]ah.InstancePlan, error) {
+	return c.cache.instancePlans.get(func() ([]ah.InstancePlan, error) {
+		return c.client.InstancePlans.List(ctx)
+	})
+}
+
+func (c *CombinedConfig) volumePlans(ctx context.Context) ([]ah.VolumePlan, error) {
+	return c.cache.volumePlans.get(func() ([]ah.VolumePlan, error) {
+		return c.client.VolumePlans.List(ctx)
+	})
+}
+
+func (c *CombinedConfig) images() ([]ah.Image, error) {
+	return c.cache.images.get(func() ([]ah.Image, error) {
+		return allImages(c.client, &ah.ListOptions{})
+	})
+}
+
+func (c *CombinedConfig) k8sVersions(ctx context.Context) ([]string, error) {
+	return c.cache.k8sVersions.get(func() ([]string, error) {
+		return c.client.KubernetesClusters.GetKubernetesClustersVersions(ctx)
+	})
+}
+
+func (c *CombinedConfig) sshKeys() ([]ah.SSHKey, error) {
+	return c.cache.sshKeys.get(func() ([]ah.SSHKey, error) {
+		return allSSHKeysInfo(c.client, &ah.ListOptions{})
+	})
+}
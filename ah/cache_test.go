@@ -0,0 +1,95 @@
+package ah
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+)
+
+// countingDatacentersAPI counts list calls to check that lookups are served from the cache.
+type countingDatacentersAPI struct {
+	ah.DatacentersAPI
+	calls int32
+	err   error
+}
+
+func (f *countingDatacentersAPI) List(ctx context.Context, options *ah.ListOptions) ([]ah.Datacenter, error) {
+	atomic.AddInt32(&f.calls, 1)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []ah.Datacenter{{ID: DatacenterID, Slug: DatacenterName}}, nil
+}
+
+func TestLookupCache_Datacenters(t *testing.T) {
+	datacenters := &countingDatacentersAPI{}
+	meta := testFakeMeta()
+	meta.client.Datacenters = datacenters
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := datacenterIDBySlug(context.Background(), meta, DatacenterName)
+			if err != nil || id != DatacenterID {
+				t.Errorf("expected datacenter %s, got %s (%v)", DatacenterID, id, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&datacenters.calls); calls != 1 {
+		t.Fatalf("expected 1 list call, got %d", calls)
+	}
+
+	meta.cache.datacenters.invalidate()
+	if _, err := datacentersInfo(meta); err != nil {
+		t.Fatal(err)
+	}
+	if calls := atomic.LoadInt32(&datacenters.calls); calls != 2 {
+		t.Fatalf("expected 2 list calls after invalidation, got %d", calls)
+	}
+}
+
+func TestLookupCache_ErrorsAreNotCached(t *testing.T) {
+	datacenters := &countingDatacentersAPI{err: errors.New("unavailable")}
+	meta := testFakeMeta()
+	meta.client.Datacenters = datacenters
+
+	if _, err := datacenterIDBySlug(context.Background(), meta, DatacenterName); err == nil {
+		t.Fatal("expected error")
+	}
+
+	datacenters.err = nil
+	if _, err := datacenterIDBySlug(context.Background(), meta, DatacenterName); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls := atomic.LoadInt32(&datacenters.calls); calls != 2 {
+		t.Fatalf("expected 2 list calls, got %d", calls)
+	}
+}
+
+func TestLookupCache_SSHKeysInvalidation(t *testing.T) {
+	meta := testFakeMeta()
+	fingerprint := "7e:ac:a8:45:83:e3:58:f5:3a:9f:dd:16:63:dc:fb:1e"
+
+	if _, err := sshKeyByFingerprint(fingerprint, meta); err != ah.ErrResourceNotFound {
+		t.Fatalf("expected ErrResourceNotFound, got %v", err)
+	}
+
+	meta.client.SSHKeys = &fakeSSHKeysAPI{sshKeys: []ah.SSHKey{{ID: "ssh-key-id", Fingerprint: fingerprint}}}
+	if _, err := sshKeyByFingerprint(fingerprint, meta); err != ah.ErrResourceNotFound {
+		t.Fatalf("expected cached result, got %v", err)
+	}
+
+	meta.cache.sshKeys.invalidate()
+	sshKey, err := sshKeyByFingerprint(fingerprint, meta)
+	if err != nil || sshKey.ID != "ssh-key-id" {
+		t.Fatalf("expected ssh key ssh-key-id, got %v (%v)", sshKey, err)
+	}
+}
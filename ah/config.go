@@ -1,22 +1,74 @@
 package ah
 
 import (
+	"sync"
+
 	"github.com/advancedhosting/advancedhosting-api-go/ah"
 )
 
 // Config represents provider's configuration
 type Config struct {
-	Token       string
-	APIEndpoint string
+	Token                    string
+	APIEndpoint              string
+	MaxMonthlyCostIncrease   *float64
+	ValidateReferencesAtPlan bool
+}
+
+// CombinedConfig is passed to resources and data sources as meta
+type CombinedConfig struct {
+	client                   *ah.APIClient
+	maxMonthlyCostIncrease   *float64
+	validateReferencesAtPlan bool
+	cache                    lookupCache
+	loadBalancerLocks        mutexKV
+}
+
+func (c *CombinedConfig) ahClient() *ah.APIClient {
+	return c.client
+}
+
+// mutexKV serializes changes made by separate resources to the same object
+type mutexKV struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func (m *mutexKV) get(key string) *sync.Mutex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.locks == nil {
+		m.locks = make(map[string]*sync.Mutex)
+	}
+	if _, ok := m.locks[key]; !ok {
+		m.locks[key] = &sync.Mutex{}
+	}
+	return m.locks[key]
+}
+
+func (m *mutexKV) Lock(key string) {
+	m.get(key).Lock()
+}
+
+func (m *mutexKV) Unlock(key string) {
+	m.get(key).Unlock()
 }
 
 // Client returns a new client to communicate with AH Cloud
-func (c *Config) Client() (*ah.APIClient, error) {
+func (c *Config) Client() (*CombinedConfig, error) {
 	clientOptions := &ah.ClientOptions{
 		Token:   c.Token,
 		BaseURL: c.APIEndpoint,
 	}
 
-	return ah.NewAPIClient(clientOptions)
+	client, err := ah.NewAPIClient(clientOptions)
+	if err != nil {
+		return nil, err
+	}
 
+	return &CombinedConfig{
+		client:                   client,
+		maxMonthlyCostIncrease:   c.MaxMonthlyCostIncrease,
+		validateReferencesAtPlan: c.ValidateReferencesAtPlan,
+	}, nil
 }
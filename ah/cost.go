@@ -0,0 +1,263 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	cloudServerPriceType = "monthly,vps"
+	volumePriceType      = "overuse,volume_du"
+
+	// hoursPerMonth converts monthly plan prices to hourly costs
+	hoursPerMonth = 730
+)
+
+// monthlyCost is a monthly price together with its currency
+type monthlyCost struct {
+	amount   float64
+	currency string
+}
+
+func planPrice(plan ah.Plan, priceType string) (monthlyCost, error) {
+	for _, price := range plan.Prices {
+		if price.Type != priceType {
+			continue
+		}
+		amount, err := strconv.ParseFloat(price.Price, 64)
+		if err != nil {
+			return monthlyCost{}, fmt.Errorf("invalid %s price %q of plan %d: %s", priceType, price.Price, plan.ID, err)
+		}
+		currency := price.Currency
+		if currency == "" {
+			currency = plan.Currency
+		}
+		return monthlyCost{amount: amount, currency: currency}, nil
+	}
+	return monthlyCost{}, fmt.Errorf("plan %d has no %s price", plan.ID, priceType)
+}
+
+func cloudServerMonthlyCost(meta interface{}, planAttr string) (monthlyCost, error) {
+	plan, err := cloudServerPlanByAttr(meta, planAttr)
+	if err != nil {
+		return monthlyCost{}, err
+	}
+	return planPrice(plan.Plan, cloudServerPriceType)
+}
+
+func volumePlanByAttr(meta interface{}, planAttr string) (*ah.VolumePlan, error) {
+	volumePlans, err := allVolumePlans(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	planID, _ := strconv.Atoi(planAttr)
+	for _, volumePlan := range volumePlans {
+		if volumePlan.ID == planID ||
+			volumePlan.CustomAttributes.Slug == planAttr ||
+			volumePlan.CustomAttributes.WebsaProductId == planAttr {
+			return &volumePlan, nil
+		}
+	}
+	return nil, fmt.Errorf("volume plan %s not found", planAttr)
+}
+
+// volumeMonthlyCost returns the cost of a volume, volume plans are priced per GB.
+func volumeMonthlyCost(meta interface{}, planAttr string, size int) (monthlyCost, error) {
+	plan, err := volumePlanByAttr(meta, planAttr)
+	if err != nil {
+		return monthlyCost{}, err
+	}
+	price, err := planPrice(plan.Plan, volumePriceType)
+	if err != nil {
+		return monthlyCost{}, err
+	}
+	price.amount *= float64(size)
+	return price, nil
+}
+
+// nodePoolsMonthlyCost returns the cost of public k8s node pools. Private node pools run on
+// private cloud capacity that isn't priced by plans, so they are left out.
+func nodePoolsMonthlyCost(meta interface{}, nodePools []interface{}) (monthlyCost, error) {
+	var cost monthlyCost
+	if len(nodePools) == 0 {
+		return cost, nil
+	}
+
+	cloudServerPlans, err := allCloudServerPlans(meta)
+	if err != nil {
+		return monthlyCost{}, err
+	}
+	plans := make(map[int]ah.Plan, len(cloudServerPlans))
+	for _, plan := range cloudServerPlans {
+		plans[plan.ID] = plan.Plan
+	}
+
+	for _, np := range nodePools {
+		nodePool, ok := np.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		publicProperties, _ := nodePool["public_properties"].(map[string]interface{})
+		planID, _ := publicProperties["plan_id"].(int)
+		if planID == 0 {
+			continue
+		}
+
+		count, _ := nodePool["nodes_count"].(int)
+		if autoScale, _ := nodePool["auto_scale"].(bool); autoScale {
+			count, _ = nodePool["max_count"].(int)
+		}
+
+		plan, ok := plans[planID]
+		if !ok {
+			log.Printf("[DEBUG] no price data for k8s node pool plan %d", planID)
+			continue
+		}
+		price, err := planPrice(plan, cloudServerPriceType)
+		if err != nil {
+			return monthlyCost{}, err
+		}
+		cost.amount += price.amount * float64(count)
+		cost.currency = price.currency
+	}
+	return cost, nil
+}
+
+// costBudget returns max_monthly_cost_increase and whether it is set. Zero allows no
+// increase at all.
+func costBudget(meta interface{}) (float64, bool) {
+	maxIncrease := meta.(*CombinedConfig).maxMonthlyCostIncrease
+	if maxIncrease == nil {
+		return 0, false
+	}
+	return *maxIncrease, true
+}
+
+// costLookupFailed fails the plan when the budget is enforced. Without a budget the cost is
+// left unknown, so a plan missing from the price list never blocks a change.
+func costLookupFailed(d *schema.ResourceDiff, meta interface{}, resourceName string, err error) error {
+	if _, enforced := costBudget(meta); enforced {
+		return err
+	}
+	log.Printf("[DEBUG] can't estimate the monthly cost of %s: %s", resourceName, err)
+	return d.SetNewComputed("monthly_cost")
+}
+
+// storedMonthlyCost is the cost planned for the resource before, used when its old plan has
+// been retired from the price list.
+func storedMonthlyCost(d *schema.ResourceDiff, resourceName string, err error) monthlyCost {
+	log.Printf("[DEBUG] using the stored monthly cost of %s %s: %s", resourceName, d.Id(), err)
+	oldCost, _ := d.GetChange("monthly_cost")
+	return monthlyCost{amount: oldCost.(float64)}
+}
+
+// checkMonthlyCostIncrease plans monthly_cost of a resource and fails when the increase
+// exceeds max_monthly_cost_increase of the provider.
+func checkMonthlyCostIncrease(d *schema.ResourceDiff, meta interface{}, resourceName string, oldCost, newCost monthlyCost) error {
+	currency := newCost.currency
+	if currency == "" {
+		currency = oldCost.currency
+	}
+	increase := newCost.amount - oldCost.amount
+
+	name := resourceName
+	if d.Id() != "" {
+		name = fmt.Sprintf("%s %s", resourceName, d.Id())
+	}
+
+	if err := d.SetNew("monthly_cost", roundCost(newCost.amount)); err != nil {
+		return err
+	}
+
+	maxIncrease, enforced := costBudget(meta)
+	if enforced && increase > maxIncrease {
+		return fmt.Errorf("monthly cost of %s increases by %.2f %s (from %.2f to %.2f), above max_monthly_cost_increase %.2f",
+			name, increase, currency, oldCost.amount, newCost.amount, maxIncrease)
+	}
+	return nil
+}
+
+// cloudServerCostDiff plans the cost of plan changes. Servers configured with the deprecated
+// product argument and private cloud servers have no plan price and are skipped.
+func cloudServerCostDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("private_cloud").(bool) {
+		return nil
+	}
+
+	if (d.Id() != "" && !d.HasChange("plan")) || !d.NewValueKnown("plan") {
+		return nil
+	}
+
+	oldPlanAttr, newPlanAttr := d.GetChange("plan")
+	if newPlanAttr.(string) == "" {
+		return nil
+	}
+
+	newCost, err := cloudServerMonthlyCost(meta, newPlanAttr.(string))
+	if err != nil {
+		return costLookupFailed(d, meta, "cloud server", err)
+	}
+	var oldCost monthlyCost
+	if d.Id() != "" && oldPlanAttr.(string) != "" {
+		if oldCost, err = cloudServerMonthlyCost(meta, oldPlanAttr.(string)); err != nil {
+			oldCost = storedMonthlyCost(d, "cloud server", err)
+		}
+	}
+
+	return checkMonthlyCostIncrease(d, meta, "cloud server", oldCost, newCost)
+}
+
+func volumeCostDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if (d.Id() != "" && !d.HasChanges("plan", "size")) || !d.NewValueKnown("plan") || !d.NewValueKnown("size") {
+		return nil
+	}
+
+	oldPlanAttr, newPlanAttr := d.GetChange("plan")
+	oldSize, newSize := d.GetChange("size")
+	if newPlanAttr.(string) == "" || newSize.(int) == 0 {
+		return nil
+	}
+
+	newCost, err := volumeMonthlyCost(meta, newPlanAttr.(string), newSize.(int))
+	if err != nil {
+		return costLookupFailed(d, meta, "volume", err)
+	}
+	var oldCost monthlyCost
+	if d.Id() != "" && oldPlanAttr.(string) != "" {
+		if oldCost, err = volumeMonthlyCost(meta, oldPlanAttr.(string), oldSize.(int)); err != nil {
+			oldCost = storedMonthlyCost(d, "volume", err)
+		}
+	}
+
+	return checkMonthlyCostIncrease(d, meta, "volume", oldCost, newCost)
+}
+
+func k8sClusterCostDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if (d.Id() != "" && !d.HasChange("node_pools")) || !d.NewValueKnown("node_pools") {
+		return nil
+	}
+
+	oldNodePools, newNodePools := d.GetChange("node_pools")
+
+	newCost, err := nodePoolsMonthlyCost(meta, newNodePools.([]interface{}))
+	if err != nil {
+		return costLookupFailed(d, meta, "k8s cluster", err)
+	}
+	var oldCost monthlyCost
+	if d.Id() != "" {
+		if oldCost, err = nodePoolsMonthlyCost(meta, oldNodePools.([]interface{})); err != nil {
+			oldCost = storedMonthlyCost(d, "k8s cluster", err)
+		}
+	}
+	if oldCost.amount == 0 && newCost.amount == 0 {
+		return nil
+	}
+
+	return checkMonthlyCostIncrease(d, meta, "k8s cluster", oldCost, newCost)
+}
@@ -0,0 +1,143 @@
+package ah
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestMonthlyCostIncrease(t *testing.T) {
+	server := map[string]interface{}{
+		"name":       "test",
+		"datacenter": DatacenterName,
+		"image":      ImageName,
+		"plan":       "start-m",
+	}
+	unpricedServer := map[string]interface{}{
+		"name":       "test",
+		"datacenter": DatacenterName,
+		"image":      ImageName,
+		"plan":       "unknown-plan",
+	}
+	volume := map[string]interface{}{
+		"name": "test",
+		"plan": "ssd2-ash1",
+		"size": 100,
+	}
+	k8sCluster := map[string]interface{}{
+		"name":        "test",
+		"datacenter":  DatacenterName,
+		"k8s_version": "1.19.3",
+		"node_pools": []interface{}{
+			map[string]interface{}{
+				"type":              "public",
+				"nodes_count":       3,
+				"public_properties": map[string]interface{}{"plan_id": 2},
+			},
+		},
+	}
+
+	budget := func(maxIncrease float64) *float64 {
+		return &maxIncrease
+	}
+
+	cases := []struct {
+		name        string
+		config      map[string]interface{}
+		resource    *schema.Resource
+		maxIncrease *float64
+		expectedErr string
+		monthlyCost string
+	}{
+		{"server without budget", server, resourceAHCloudServer(), nil, "", "10"},
+		{"unknown plan without budget", unpricedServer, resourceAHCloudServer(), nil, "", ""},
+		{"unknown plan with budget", unpricedServer, resourceAHCloudServer(), budget(10), "unknown-plan", ""},
+		{"server within budget", server, resourceAHCloudServer(), budget(10), "", "10"},
+		{"server above budget", server, resourceAHCloudServer(), budget(9.5), "monthly cost of cloud server increases by 10.00 usd (from 0.00 to 10.00)", ""},
+		{"server with zero budget", server, resourceAHCloudServer(), budget(0), "above max_monthly_cost_increase 0.00", ""},
+		{"volume within budget", volume, resourceAHVolume(), budget(10), "", "10"},
+		{"volume above budget", volume, resourceAHVolume(), budget(5), "monthly cost of volume increases by 10.00 usd", ""},
+		{"k8s cluster within budget", k8sCluster, resourceAHK8sCluster(), budget(30), "", "30"},
+		{"k8s cluster above budget", k8sCluster, resourceAHK8sCluster(), budget(20), "monthly cost of k8s cluster increases by 30.00 usd", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			meta := testFakeMeta()
+			meta.maxMonthlyCostIncrease = tc.maxIncrease
+
+			diff, err := testResourceDiff(t, tc.resource, tc.config, meta)
+			if tc.expectedErr == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedErr)) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			monthlyCost := diff.Attributes["monthly_cost"]
+			if tc.monthlyCost == "" {
+				if monthlyCost == nil || !monthlyCost.NewComputed {
+					t.Fatalf("expected unknown monthly_cost, got %#v", monthlyCost)
+				}
+			} else if monthlyCost == nil || monthlyCost.New != tc.monthlyCost {
+				t.Fatalf("expected monthly_cost %s, got %#v", tc.monthlyCost, monthlyCost)
+			}
+		})
+	}
+}
+
+func TestMonthlyCostIncrease_Update(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "instance-id",
+		Attributes: map[string]string{
+			"id":         "instance-id",
+			"name":       "test",
+			"datacenter": DatacenterName,
+			"image":      ImageName,
+			"plan":       "start-m",
+		},
+	}
+	config := map[string]interface{}{
+		"name":       "test",
+		"datacenter": DatacenterName,
+		"image":      ImageName,
+		"plan":       "cpu-m",
+	}
+	state.RawConfig = testResourceConfig(t, resourceAHCloudServer(), config)
+
+	meta := testFakeMeta()
+	maxIncrease := 5.0
+	meta.maxMonthlyCostIncrease = &maxIncrease
+	_, err := resourceAHCloudServer().Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	if err == nil || !strings.Contains(err.Error(), "monthly cost of cloud server instance-id increases by 10.00 usd (from 10.00 to 20.00)") {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+
+	maxIncrease = 10
+	diff, err := resourceAHCloudServer().Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if monthlyCost := diff.Attributes["monthly_cost"]; monthlyCost == nil || monthlyCost.New != "20" {
+		t.Fatalf("expected monthly_cost 20, got %#v", monthlyCost)
+	}
+
+	// A retired old plan is priced from the monthly_cost planned before
+	state.Attributes["plan"] = "retired-plan"
+	state.Attributes["monthly_cost"] = "15"
+	maxIncrease = 5
+	if _, err := resourceAHCloudServer().Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	maxIncrease = 4
+	_, err = resourceAHCloudServer().Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	if err == nil || !strings.Contains(err.Error(), "increases by 5.00 usd (from 15.00 to 20.00)") {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+}
@@ -65,9 +65,7 @@ func dataSourceAHCloudServerPlans() *schema.Resource {
 }
 
 func dataSourceAHCloudServerPlansRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
-
-	cloudServerPlans, err := allCloudServerPlans(client)
+	cloudServerPlans, err := allCloudServerPlans(meta)
 	if err != nil {
 		return err
 	}
@@ -114,13 +112,35 @@ func dataSourceAHCloudServerPlansSchema(d *schema.ResourceData, cloudServerPlans
 	return nil
 }
 
-func allCloudServerPlans(client *ah.APIClient) ([]ah.InstancePlan, error) {
-
-	cloudServerPlans, err := client.InstancePlans.List(context.Background())
+func allCloudServerPlans(meta interface{}) ([]ah.InstancePlan, error) {
+	return meta.(*CombinedConfig).instancePlans(context.Background())
+}
 
+func cloudServerPlanByAttr(meta interface{}, planAttr string) (*ah.InstancePlan, error) {
+	cloudServerPlans, err := allCloudServerPlans(meta)
 	if err != nil {
 		return nil, err
 	}
 
-	return cloudServerPlans, nil
+	planID, _ := strconv.Atoi(planAttr)
+	for _, cloudServerPlan := range cloudServerPlans {
+		if cloudServerPlan.ID == planID ||
+			cloudServerPlan.CustomAttributes.Slug == planAttr ||
+			cloudServerPlan.CustomAttributes.WebsaProductId == planAttr {
+			return &cloudServerPlan, nil
+		}
+	}
+	return nil, fmt.Errorf("cloud server plan %s not found", planAttr)
+}
+
+func cloudServerPlanSpecs(plan *ah.InstancePlan) (vcpu, ram, disk int) {
+	vcpu, _ = strconv.Atoi(plan.CustomAttributes.Vcpu)
+	ram, _ = strconv.Atoi(plan.CustomAttributes.RAM)
+	disk, _ = strconv.Atoi(plan.CustomAttributes.Disk)
+	return
+}
+
+func describeCloudServerPlan(plan *ah.InstancePlan) string {
+	vcpu, ram, disk := cloudServerPlanSpecs(plan)
+	return fmt.Sprintf("%s (vcpu: %d, ram: %d, disk: %d)", plan.CustomAttributes.Slug, vcpu, ram, disk)
 }
@@ -121,7 +121,7 @@ func buildAHCloudServerSnapshotsAndBackupsListFilter(set *schema.Set) []ah.Filte
 }
 
 func dataSourceAHCloudServerSnapshotsAndBackupsRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	options := &ah.ListOptions{}
 
 	if v, ok := d.GetOk("filter"); ok {
@@ -199,7 +199,7 @@ func buildListSorting(set *schema.Set) []*ah.Sorting {
 }
 
 func dataSourceAHCloudServersRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	var listFilters []ah.FilterInterface
 	if v, ok := d.GetOk("filter"); ok {
@@ -258,7 +258,7 @@ func allCloudServers(client *ah.APIClient, listFilters []ah.FilterInterface, lis
 }
 
 func cloudServersSchema(instances []ah.Instance, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	cloudServers := make([]map[string]interface{}, len(instances))
 	var ids string
 	for i, instance := range instances {
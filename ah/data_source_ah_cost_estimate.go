@@ -0,0 +1,190 @@
+package ah
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAHCostEstimate() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceAHCostEstimateRead,
+		Schema: map[string]*schema.Schema{
+			"cloud_servers": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: costEstimateItemSchema(map[string]*schema.Schema{
+						"plan": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"count": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+					}),
+				},
+			},
+			"volumes": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: costEstimateItemSchema(map[string]*schema.Schema{
+						"plan": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"size": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"count": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+					}),
+				},
+			},
+			"k8s_node_pools": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: costEstimateItemSchema(map[string]*schema.Schema{
+						"plan_id": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"nodes_count": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+					}),
+				},
+			},
+			"total_monthly_cost": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+			"total_hourly_cost": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+			"currency": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// costEstimateItemSchema adds the computed cost attributes to the item arguments.
+func costEstimateItemSchema(arguments map[string]*schema.Schema) map[string]*schema.Schema {
+	arguments["monthly_cost"] = &schema.Schema{
+		Type:     schema.TypeFloat,
+		Computed: true,
+	}
+	arguments["hourly_cost"] = &schema.Schema{
+		Type:     schema.TypeFloat,
+		Computed: true,
+	}
+	return arguments
+}
+
+func dataSourceAHCostEstimateRead(d *schema.ResourceData, meta interface{}) error {
+
+	estimate := &costEstimate{}
+	var ids string
+
+	cloudServers := d.Get("cloud_servers").([]interface{})
+	for i, cs := range cloudServers {
+		cloudServer := cs.(map[string]interface{})
+		price, err := cloudServerMonthlyCost(meta, cloudServer["plan"].(string))
+		if err != nil {
+			return err
+		}
+		if err := estimate.add(cloudServer, price, cloudServer["count"].(int)); err != nil {
+			return err
+		}
+		cloudServers[i] = cloudServer
+		ids += fmt.Sprintf("cs%s:%d", cloudServer["plan"], cloudServer["count"])
+	}
+
+	volumes := d.Get("volumes").([]interface{})
+	for i, v := range volumes {
+		volume := v.(map[string]interface{})
+		price, err := volumeMonthlyCost(meta, volume["plan"].(string), volume["size"].(int))
+		if err != nil {
+			return err
+		}
+		if err := estimate.add(volume, price, volume["count"].(int)); err != nil {
+			return err
+		}
+		volumes[i] = volume
+		ids += fmt.Sprintf("v%s:%d:%d", volume["plan"], volume["size"], volume["count"])
+	}
+
+	nodePools := d.Get("k8s_node_pools").([]interface{})
+	for i, np := range nodePools {
+		nodePool := np.(map[string]interface{})
+		price, err := cloudServerMonthlyCost(meta, strconv.Itoa(nodePool["plan_id"].(int)))
+		if err != nil {
+			return err
+		}
+		if err := estimate.add(nodePool, price, nodePool["nodes_count"].(int)); err != nil {
+			return err
+		}
+		nodePools[i] = nodePool
+		ids += fmt.Sprintf("np%d:%d", nodePool["plan_id"], nodePool["nodes_count"])
+	}
+
+	if err := d.Set("cloud_servers", cloudServers); err != nil {
+		return fmt.Errorf("unable to set cloud_servers attribute: %s", err)
+	}
+	if err := d.Set("volumes", volumes); err != nil {
+		return fmt.Errorf("unable to set volumes attribute: %s", err)
+	}
+	if err := d.Set("k8s_node_pools", nodePools); err != nil {
+		return fmt.Errorf("unable to set k8s_node_pools attribute: %s", err)
+	}
+	d.Set("total_monthly_cost", roundCost(estimate.total))
+	d.Set("total_hourly_cost", roundCost(estimate.total/hoursPerMonth))
+	d.Set("currency", estimate.currency)
+	d.SetId(generateHash(ids))
+	return nil
+}
+
+// costEstimate sums up the cost of items that must share a single currency.
+type costEstimate struct {
+	total    float64
+	currency string
+}
+
+func (e *costEstimate) add(item map[string]interface{}, price monthlyCost, count int) error {
+	if e.currency != "" && price.currency != e.currency {
+		return fmt.Errorf("can't estimate costs in different currencies: %s and %s", e.currency, price.currency)
+	}
+	e.currency = price.currency
+
+	monthly := price.amount * float64(count)
+	item["monthly_cost"] = roundCost(monthly)
+	item["hourly_cost"] = roundCost(monthly / hoursPerMonth)
+	e.total += monthly
+	return nil
+}
+
+func roundCost(cost float64) float64 {
+	return math.Round(cost*10000) / 10000
+}
@@ -0,0 +1,86 @@
+package ah
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccDataSourceAHCostEstimate_Basic(t *testing.T) {
+	datasourceConfig := fmt.Sprintf(`
+	data "ah_cost_estimate" "test" {
+	  cloud_servers {
+	    plan  = "%s"
+	    count = 2
+	  }
+	  volumes {
+	    plan = "%s"
+	    size = 20
+	  }
+	}`, VpsPlanName, VolumePlanName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: datasourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.ah_cost_estimate.test", "cloud_servers.0.monthly_cost"),
+					resource.TestCheckResourceAttrSet("data.ah_cost_estimate.test", "cloud_servers.0.hourly_cost"),
+					resource.TestCheckResourceAttrSet("data.ah_cost_estimate.test", "volumes.0.monthly_cost"),
+					resource.TestCheckResourceAttrSet("data.ah_cost_estimate.test", "total_monthly_cost"),
+					resource.TestCheckResourceAttrSet("data.ah_cost_estimate.test", "total_hourly_cost"),
+					resource.TestCheckResourceAttrSet("data.ah_cost_estimate.test", "currency"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceAHCostEstimate_Read(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceAHCostEstimate().Schema, map[string]interface{}{
+		"cloud_servers": []interface{}{
+			map[string]interface{}{"plan": "start-m", "count": 2},
+		},
+		"volumes": []interface{}{
+			map[string]interface{}{"plan": "ssd2-ash1", "size": 100},
+		},
+		"k8s_node_pools": []interface{}{
+			map[string]interface{}{"plan_id": 1, "nodes_count": 3},
+		},
+	})
+
+	if err := dataSourceAHCostEstimateRead(d, testFakeMeta()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"cloud_servers.0.monthly_cost":  20.0,
+		"volumes.0.monthly_cost":        10.0,
+		"k8s_node_pools.0.monthly_cost": 15.0,
+		"k8s_node_pools.0.hourly_cost":  0.0205,
+		"total_monthly_cost":            45.0,
+		"total_hourly_cost":             0.0616,
+		"currency":                      "usd",
+	}
+	for key, value := range expected {
+		if d.Get(key) != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, d.Get(key))
+		}
+	}
+}
+
+func TestDataSourceAHCostEstimate_UnknownPlan(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceAHCostEstimate().Schema, map[string]interface{}{
+		"cloud_servers": []interface{}{
+			map[string]interface{}{"plan": "unknown"},
+		},
+	})
+
+	if err := dataSourceAHCostEstimateRead(d, testFakeMeta()); err == nil {
+		t.Fatal("expected error for unknown plan")
+	}
+}
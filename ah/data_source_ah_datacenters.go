@@ -105,7 +105,7 @@ func buildAHDatacentersListFilter(set *schema.Set) []ah.FilterInterface {
 }
 
 func dataSourceAHDatacentersRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	options := &ah.ListOptions{}
 
 	if v, ok := d.GetOk("filter"); ok {
@@ -90,7 +90,7 @@ func buildAHImagesListFilter(set *schema.Set) []ah.FilterInterface {
 }
 
 func dataSourceAHImagesRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	options := &ah.ListOptions{}
 
 	if v, ok := d.GetOk("filter"); ok {
@@ -122,7 +122,7 @@ func buildAHIPListSorting(set *schema.Set) []*ah.Sorting {
 }
 
 func dataSourceAHIPsRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	options := &ah.ListOptions{}
 
 	if v, ok := d.GetOk("filter"); ok {
@@ -172,7 +172,7 @@ func dataSourceAHIPsSchema(d *schema.ResourceData, meta interface{}, ipAddresses
 }
 
 func isPrimaryIP(ipAddress *ah.IPAddress, meta interface{}) (bool, error) {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if ipAddress.Type != "public" {
 		return false, fmt.Errorf("IP with type `%s` can not be primary", ipAddress.Type)
 	}
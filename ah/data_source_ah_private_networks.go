@@ -117,7 +117,7 @@ func buildAHPrivateNetworksListFilter(set *schema.Set) []ah.FilterInterface {
 }
 
 func dataSourceAHPrivateNetworksRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	options := &ah.ListOptions{}
 
 	if v, ok := d.GetOk("filter"); ok {
@@ -140,7 +140,7 @@ func dataSourceAHPrivateNetworksRead(d *schema.ResourceData, meta interface{}) e
 }
 
 func dataSourceAHPrivateNetworksSchema(d *schema.ResourceData, meta interface{}, privateNetworks []ah.PrivateNetwork) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	pns := make([]map[string]interface{}, len(privateNetworks))
 	var ids string
 	for i, privateNetwork := range privateNetworks {
@@ -83,7 +83,7 @@ func buildAHSSHKeysListFilter(set *schema.Set) []ah.FilterInterface {
 }
 
 func dataSourceAHSSHKeysRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	options := &ah.ListOptions{}
 
 	if v, ok := d.GetOk("filter"); ok {
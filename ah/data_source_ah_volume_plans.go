@@ -9,7 +9,7 @@ import (
 )
 
 func dataSourceAHVolumePlans() *schema.Resource {
-	allowedFilterKeys := []string{"id", "name", "slug", "price", "currency", "min_size", "max_size", "datacenter_id", "datacenter_slug"}
+	allowedFilterKeys := []string{"id", "name", "slug", "price", "currency", "min_size", "max_size", "datacenter_id", "datacenter_slug", "datacenter_ids", "datacenter_slugs"}
 	allowedSortingKeys := []string{"id", "name", "slug", "price", "currency", "min_size", "max_size", "datacenter_id", "datacenter_slug"}
 	return &schema.Resource{
 		Read: dataSourceAHVolumePlansRead,
@@ -57,6 +57,16 @@ func dataSourceAHVolumePlans() *schema.Resource {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
+						"datacenter_ids": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"datacenter_slugs": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
 					},
 				},
 			},
@@ -65,38 +75,50 @@ func dataSourceAHVolumePlans() *schema.Resource {
 }
 
 func dataSourceAHVolumePlansRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
 
-	VolumePlans, err := allVolumePlans(client)
+	VolumePlans, err := allVolumePlans(meta)
 	if err != nil {
 		return err
 	}
 
-	if err = dataSourceAHVolumePlansSchema(d, VolumePlans, client); err != nil {
+	if err = dataSourceAHVolumePlansSchema(d, VolumePlans, meta); err != nil {
 		return err
 	}
 	return nil
 }
 
-func dataSourceAHVolumePlansSchema(d *schema.ResourceData, VolumePlans []ah.VolumePlan, client *ah.APIClient) error {
+func dataSourceAHVolumePlansSchema(d *schema.ResourceData, VolumePlans []ah.VolumePlan, meta interface{}) error {
 	var volumePlansData = make([]map[string]interface{}, len(VolumePlans))
-	datacenters, err := datacentersInfo(client)
+	datacenters, err := datacentersInfo(meta)
 	if err != nil {
 		return err
 	}
 
 	var ids string
 	for i, volumePlan := range VolumePlans {
-		datacenterID := volumePlan.CustomAttributes.DatacenterIds[0]
+		datacenterIDs := volumePlan.CustomAttributes.DatacenterIds
+		datacenterSlugs := make([]string, len(datacenterIDs))
+		for j, datacenterID := range datacenterIDs {
+			datacenterSlugs[j] = datacenters[datacenterID].Slug
+		}
+
 		volumePlanInfo := map[string]interface{}{
-			"id":              volumePlan.ID,
-			"name":            volumePlan.Name,
-			"slug":            volumePlan.CustomAttributes.Slug,
-			"currency":        volumePlan.Currency,
-			"min_size":        volumePlan.CustomAttributes.MinSize,
-			"max_size":        volumePlan.CustomAttributes.MaxSize,
-			"datacenter_id":   datacenterID,
-			"datacenter_slug": datacenters[datacenterID].Slug,
+			"id":               volumePlan.ID,
+			"name":             volumePlan.Name,
+			"slug":             volumePlan.CustomAttributes.Slug,
+			"currency":         volumePlan.Currency,
+			"min_size":         volumePlan.CustomAttributes.MinSize,
+			"max_size":         volumePlan.CustomAttributes.MaxSize,
+			"datacenter_ids":   datacenterIDs,
+			"datacenter_slugs": datacenterSlugs,
+		}
+		// datacenter_id and datacenter_slug keep the first datacenter for existing configurations
+		if len(datacenterIDs) > 0 {
+			volumePlanInfo["datacenter_id"] = datacenterIDs[0]
+			volumePlanInfo["datacenter_slug"] = datacenterSlugs[0]
+		} else {
+			volumePlanInfo["datacenter_id"] = ""
+			volumePlanInfo["datacenter_slug"] = ""
 		}
 
 		for _, price := range volumePlan.Prices {
@@ -119,19 +141,12 @@ func dataSourceAHVolumePlansSchema(d *schema.ResourceData, VolumePlans []ah.Volu
 	return nil
 }
 
-func allVolumePlans(client *ah.APIClient) ([]ah.VolumePlan, error) {
-
-	VolumePlans, err := client.VolumePlans.List(context.Background())
-
-	if err != nil {
-		return nil, err
-	}
-
-	return VolumePlans, nil
+func allVolumePlans(meta interface{}) ([]ah.VolumePlan, error) {
+	return meta.(*CombinedConfig).volumePlans(context.Background())
 }
 
-func datacentersInfo(client *ah.APIClient) (map[string]ah.Datacenter, error) {
-	datacenters, err := client.Datacenters.List(context.Background(), nil)
+func datacentersInfo(meta interface{}) (map[string]ah.Datacenter, error) {
+	datacenters, err := meta.(*CombinedConfig).datacenters(context.Background())
 	if err != nil {
 		return nil, err
 	}
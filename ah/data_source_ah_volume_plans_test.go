@@ -1,9 +1,12 @@
 package ah
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func TestAccDataSourceAHVolumePlans_Basic(t *testing.T) {
@@ -37,8 +40,52 @@ func TestAccDataSourceAHVolumePlans_Basic(t *testing.T) {
 					resource.TestCheckResourceAttrSet("data.ah_volume_plans.test", "plans.0.max_size"),
 					resource.TestCheckResourceAttrSet("data.ah_volume_plans.test", "plans.0.datacenter_id"),
 					resource.TestCheckResourceAttrSet("data.ah_volume_plans.test", "plans.0.datacenter_slug"),
+					resource.TestCheckResourceAttrSet("data.ah_volume_plans.test", "plans.0.datacenter_ids.0"),
+					resource.TestCheckResourceAttrSet("data.ah_volume_plans.test", "plans.0.datacenter_slugs.0"),
 				),
 			},
 		},
 	})
 }
+
+func TestDataSourceAHVolumePlans_MultipleDatacenters(t *testing.T) {
+	meta := testFakeMeta()
+	meta.client.Datacenters = &fakeDatacentersAPI{
+		datacenters: []ah.Datacenter{
+			{ID: "dc1", Slug: "ams1"},
+			{ID: "dc2", Slug: "ash1"},
+		},
+	}
+	volumePlans := []ah.VolumePlan{
+		testVolumePlan(11, "hdd2", "dc1", "0.05"),
+		testVolumePlan(12, "ssd2", "dc1", "0.1"),
+	}
+	volumePlans[1].CustomAttributes.DatacenterIds = []string{"dc1", "dc2"}
+
+	d := schema.TestResourceDataRaw(t, dataSourceAHVolumePlans().Schema, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{"key": "datacenter_slugs", "values": []interface{}{"ash1"}},
+		},
+	})
+	if err := dataSourceAHVolumePlansSchema(d, volumePlans, meta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	plans := d.Get("plans").([]interface{})
+	if len(plans) != 1 {
+		t.Fatalf("expected 1 plan, got %d", len(plans))
+	}
+	plan := plans[0].(map[string]interface{})
+	if plan["slug"] != "ssd2" {
+		t.Errorf("expected plan ssd2, got %s", plan["slug"])
+	}
+	if expected := []interface{}{"dc1", "dc2"}; !reflect.DeepEqual(plan["datacenter_ids"], expected) {
+		t.Errorf("expected datacenter_ids %v, got %v", expected, plan["datacenter_ids"])
+	}
+	if expected := []interface{}{"ams1", "ash1"}; !reflect.DeepEqual(plan["datacenter_slugs"], expected) {
+		t.Errorf("expected datacenter_slugs %v, got %v", expected, plan["datacenter_slugs"])
+	}
+	if plan["datacenter_id"] != "dc1" || plan["datacenter_slug"] != "ams1" {
+		t.Errorf("expected first datacenter dc1/ams1, got %s/%s", plan["datacenter_id"], plan["datacenter_slug"])
+	}
+}
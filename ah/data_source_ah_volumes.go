@@ -102,7 +102,7 @@ func buildAHVolumesListFilter(set *schema.Set) []ah.FilterInterface {
 }
 
 func dataSourceAHVolumesRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	options := &ah.ListOptions{}
 
 	if v, ok := d.GetOk("filter"); ok {
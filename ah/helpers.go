@@ -4,11 +4,11 @@ import (
 	"context"
 	"crypto/sha1"
 	"fmt"
-	"github.com/advancedhosting/advancedhosting-api-go/ah"
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"testing"
+	"time"
 )
 
 const (
@@ -34,14 +34,21 @@ func IsUUID(s string) bool {
 	return err == nil
 }
 
+func validateDuration(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a valid duration such as \"720h\": %s", k, err))
+	}
+	return
+}
+
 func generateHash(s string) string {
 	h := sha1.New()
 	h.Write([]byte(s))
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
-func datacenterIDBySlug(ctx context.Context, client *ah.APIClient, datacenterSlug string) (string, error) {
-	datacenters, err := client.Datacenters.List(ctx, nil)
+func datacenterIDBySlug(ctx context.Context, meta interface{}, datacenterSlug string) (string, error) {
+	datacenters, err := meta.(*CombinedConfig).datacenters(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -53,8 +60,8 @@ func datacenterIDBySlug(ctx context.Context, client *ah.APIClient, datacenterSlu
 	return "", fmt.Errorf("datacenter slug %s not found", datacenterSlug)
 }
 
-func kubernetesVersion(ctx context.Context, client *ah.APIClient, k8sVersion string) (string, error) {
-	versions, err := client.KubernetesClusters.GetKubernetesClustersVersions(ctx)
+func kubernetesVersion(ctx context.Context, meta interface{}, k8sVersion string) (string, error) {
+	versions, err := meta.(*CombinedConfig).k8sVersions(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,126 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// loadBalancerHealthCheckDiff validates the inline health_check block of ah_load_balancer.
+// url is taken from the configuration, its computed value in state doesn't count as set.
+func loadBalancerHealthCheckDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	hcs := d.GetRawConfig().GetAttr("health_check")
+	if hcs.IsNull() || !hcs.IsKnown() || hcs.LengthInt() == 0 {
+		return nil
+	}
+	hcConfig := hcs.Index(cty.NumberIntVal(0))
+	if hcConfig.IsNull() || !hcConfig.IsKnown() {
+		return nil
+	}
+
+	hc, ok := d.Get("health_check.0").(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if err := validateLoadBalancerHealthCheck(hcConfig.GetAttr("type"), hcConfig.GetAttr("url"), hc["interval"].(int), hc["timeout"].(int)); err != nil {
+		return fmt.Errorf("invalid health_check: %s", err)
+	}
+	return nil
+}
+
+// loadBalancerHealthCheckResourceDiff validates ah_load_balancer_health_check the same way as
+// the inline health_check block.
+func loadBalancerHealthCheckResourceDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	config := d.GetRawConfig()
+	if config.IsNull() {
+		return nil
+	}
+	return validateLoadBalancerHealthCheck(config.GetAttr("type"), config.GetAttr("url"), d.Get("interval").(int), d.Get("timeout").(int))
+}
+
+// validateLoadBalancerHealthCheck checks the configured type and url of a health check. Unknown
+// and unset intervals and timeouts are zero and skip the comparison.
+func validateLoadBalancerHealthCheck(hcType, url cty.Value, interval, timeout int) error {
+	urlSet := !url.IsNull() && (!url.IsKnown() || url.AsString() != "")
+
+	if !hcType.IsNull() && hcType.IsKnown() {
+		switch hcType.AsString() {
+		case "http":
+			if !urlSet {
+				return fmt.Errorf("url is required for http health checks")
+			}
+		case "tcp":
+			if urlSet {
+				return fmt.Errorf("url can't be set for tcp health checks")
+			}
+		}
+	}
+
+	if interval > 0 && timeout > 0 && timeout >= interval {
+		return fmt.Errorf("timeout (%d) must be less than interval (%d)", timeout, interval)
+	}
+	return nil
+}
+
+// loadBalancerProtocols are the protocols forwarding rules accept
+var loadBalancerProtocols = []string{"tcp", "http", "https"}
+
+// loadBalancerForwardingRulesDiff rejects duplicate request ports and incompatible protocols in
+// forwarding_rule blocks, so updateForwardingRules doesn't stop halfway on an API error.
+func loadBalancerForwardingRulesDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("forwarding_rule") {
+		return nil
+	}
+
+	var invalid []string
+	requestPorts := make(map[int]int)
+	for _, v := range d.Get("forwarding_rule").(*schema.Set).List() {
+		fr := v.(map[string]interface{})
+		requestPort := fr["request_port"].(int)
+		if requestPort != 0 {
+			requestPorts[requestPort]++
+		}
+		if err := validateForwardingRuleProtocols(fr["request_protocol"].(string), fr["communication_protocol"].(string)); err != nil {
+			invalid = append(invalid, fmt.Sprintf("request_port %d: %s", requestPort, err))
+		}
+	}
+
+	var duplicates []int
+	for requestPort, count := range requestPorts {
+		if count > 1 {
+			duplicates = append(duplicates, requestPort)
+		}
+	}
+	sort.Ints(duplicates)
+	for _, requestPort := range duplicates {
+		invalid = append(invalid, fmt.Sprintf("request_port %d is used by %d rules", requestPort, requestPorts[requestPort]))
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid forwarding_rule: %s", strings.Join(invalid, "; "))
+	}
+	return nil
+}
+
+func loadBalancerForwardingRuleResourceDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	return validateForwardingRuleProtocols(d.Get("request_protocol").(string), d.Get("communication_protocol").(string))
+}
+
+// validateForwardingRuleProtocols allows tcp requests to be forwarded over tcp only, and http
+// and https requests over http or https. Unknown protocols are empty and skipped.
+func validateForwardingRuleProtocols(requestProtocol, communicationProtocol string) error {
+	if requestProtocol == "" || communicationProtocol == "" {
+		return nil
+	}
+
+	compatible := requestProtocol == communicationProtocol ||
+		(requestProtocol != "tcp" && communicationProtocol != "tcp")
+	if !compatible {
+		return fmt.Errorf("%s requests can't be forwarded over %s", requestProtocol, communicationProtocol)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package ah
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestLoadBalancerHealthCheckDiff(t *testing.T) {
+	cases := []struct {
+		name        string
+		healthCheck map[string]interface{}
+		expectedErr string
+	}{
+		{"tcp", map[string]interface{}{"type": "tcp", "port": 80}, ""},
+		{"http with url", map[string]interface{}{"type": "http", "port": 80, "url": "/health"}, ""},
+		{"http without url", map[string]interface{}{"type": "http", "port": 80}, "url is required for http health checks"},
+		{"tcp with url", map[string]interface{}{"type": "tcp", "port": 80, "url": "/health"}, "url can't be set for tcp health checks"},
+		{"timeout below interval", map[string]interface{}{"type": "tcp", "port": 80, "interval": 10, "timeout": 5}, ""},
+		{"timeout equal to interval", map[string]interface{}{"type": "tcp", "port": 80, "interval": 5, "timeout": 5}, "timeout (5) must be less than interval (5)"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lbConfig := map[string]interface{}{
+				"name":           "test",
+				"datacenter":     DatacenterName,
+				"instance_count": 1,
+				"health_check":   []interface{}{tc.healthCheck},
+			}
+			_, err := testResourceDiff(t, resourceAHLoadBalancer(), lbConfig, testFakeMeta())
+			checkLoadBalancerDiffError(t, err, tc.expectedErr)
+
+			hcConfig := map[string]interface{}{"load_balancer_id": "lb-id"}
+			for k, v := range tc.healthCheck {
+				hcConfig[k] = v
+			}
+			_, err = testResourceDiff(t, resourceAHLoadBalancerHealthCheck(), hcConfig, testFakeMeta())
+			checkLoadBalancerDiffError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestLoadBalancerForwardingRulesDiff(t *testing.T) {
+	rule := func(requestProtocol string, requestPort int, communicationProtocol string, communicationPort int) map[string]interface{} {
+		return map[string]interface{}{
+			"request_protocol":       requestProtocol,
+			"request_port":           requestPort,
+			"communication_protocol": communicationProtocol,
+			"communication_port":     communicationPort,
+		}
+	}
+
+	cases := []struct {
+		name        string
+		rules       []interface{}
+		expectedErr string
+	}{
+		{"valid", []interface{}{rule("tcp", 80, "tcp", 8080), rule("https", 443, "http", 8080)}, ""},
+		{"tcp to http", []interface{}{rule("tcp", 80, "http", 8080)}, "request_port 80: tcp requests can't be forwarded over http"},
+		{"http to tcp", []interface{}{rule("http", 80, "tcp", 8080)}, "request_port 80: http requests can't be forwarded over tcp"},
+		{"duplicate request port", []interface{}{rule("tcp", 80, "tcp", 8080), rule("tcp", 80, "tcp", 8081)}, "request_port 80 is used by 2 rules"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lbConfig := map[string]interface{}{
+				"name":            "test",
+				"datacenter":      DatacenterName,
+				"instance_count":  1,
+				"forwarding_rule": tc.rules,
+			}
+			_, err := testResourceDiff(t, resourceAHLoadBalancer(), lbConfig, testFakeMeta())
+			checkLoadBalancerDiffError(t, err, tc.expectedErr)
+
+			if len(tc.rules) != 1 {
+				return
+			}
+			frConfig := map[string]interface{}{"load_balancer_id": "lb-id"}
+			for k, v := range tc.rules[0].(map[string]interface{}) {
+				frConfig[k] = v
+			}
+			// ah_load_balancer_forwarding_rule reports the error without the request port
+			expectedErr := tc.expectedErr
+			if i := strings.Index(expectedErr, ": "); i >= 0 {
+				expectedErr = expectedErr[i+2:]
+			}
+			_, err = testResourceDiff(t, resourceAHLoadBalancerForwardingRule(), frConfig, testFakeMeta())
+			checkLoadBalancerDiffError(t, err, expectedErr)
+		})
+	}
+}
+
+func TestLoadBalancerForwardingRuleValidation(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   string
+		value interface{}
+	}{
+		{"unknown protocol", "request_protocol", "udp"},
+		{"port out of range", "request_port", 70000},
+		{"zero port", "communication_port", 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"load_balancer_id":       "lb-id",
+				"request_protocol":       "tcp",
+				"request_port":           80,
+				"communication_protocol": "tcp",
+				"communication_port":     8080,
+			}
+			config[tc.key] = tc.value
+			if diags := resourceAHLoadBalancerForwardingRule().Validate(terraform.NewResourceConfigRaw(config)); !diags.HasError() {
+				t.Fatalf("expected %s = %v to be rejected", tc.key, tc.value)
+			}
+		})
+	}
+}
+
+func checkLoadBalancerDiffError(t *testing.T, err error, expectedErr string) {
+	t.Helper()
+	if expectedErr == "" && err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expectedErr != "" && (err == nil || !strings.Contains(err.Error(), expectedErr)) {
+		t.Fatalf("expected error %q, got %v", expectedErr, err)
+	}
+}
@@ -76,6 +76,14 @@ func filterPlanValue(v interface{}, values map[string]bool) bool {
 		}
 		_, ok := values[vBool]
 		return ok
+	case []string:
+		// list attributes match when any of their elements matches
+		for _, e := range t {
+			if _, ok := values[e]; ok {
+				return true
+			}
+		}
+		return false
 	default:
 		panic("type is not supported")
 	}
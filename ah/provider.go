@@ -2,6 +2,7 @@ package ah
 
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // Provider returns a terraform.ResourceProvider.
@@ -20,6 +21,18 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("AH_API_URL", nil),
 			},
+			"max_monthly_cost_increase": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "Fail the plan when a resource change increases the monthly cost by more than this amount.",
+			},
+			"validate_references_at_plan": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Resolve datacenter, image, plan and SSH key references of cloud servers during plan.",
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
@@ -35,19 +48,24 @@ func Provider() *schema.Provider {
 			"ah_cloud_server_products":             dataSourceAHCloudServerProducts(),
 			"ah_cloud_server_plans":                dataSourceAHCloudServerPlans(),
 			"ah_volume_plans":                      dataSourceAHVolumePlans(),
+			"ah_cost_estimate":                     dataSourceAHCostEstimate(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"ah_cloud_server":               resourceAHCloudServer(),
-			"ah_ip":                         resourceAHIP(),
-			"ah_ip_assignment":              resourceAHIPAssignment(),
-			"ah_private_network":            resourceAHPrivateNetwork(),
-			"ah_private_network_connection": resourceAHPrivateNetworkConnection(),
-			"ah_volume":                     resourceAHVolume(),
-			"ah_volume_attachment":          resourceAHVolumeAttachment(),
-			"ah_cloud_server_snapshot":      resourceAHCloudServerSnapshot(),
-			"ah_ssh_key":                    resourceAHSSHKey(),
-			"ah_load_balancer":              resourceAHLoadBalancer(),
-			"ah_k8s_cluster":                resourceAHK8sCluster(),
+			"ah_cloud_server":                  resourceAHCloudServer(),
+			"ah_ip":                            resourceAHIP(),
+			"ah_ip_assignment":                 resourceAHIPAssignment(),
+			"ah_private_network":               resourceAHPrivateNetwork(),
+			"ah_private_network_connection":    resourceAHPrivateNetworkConnection(),
+			"ah_volume":                        resourceAHVolume(),
+			"ah_volume_attachment":             resourceAHVolumeAttachment(),
+			"ah_cloud_server_snapshot":         resourceAHCloudServerSnapshot(),
+			"ah_cloud_server_snapshot_policy":  resourceAHCloudServerSnapshotPolicy(),
+			"ah_ssh_key":                       resourceAHSSHKey(),
+			"ah_load_balancer":                 resourceAHLoadBalancer(),
+			"ah_load_balancer_backend_node":    resourceAHLoadBalancerBackendNode(),
+			"ah_load_balancer_forwarding_rule": resourceAHLoadBalancerForwardingRule(),
+			"ah_load_balancer_health_check":    resourceAHLoadBalancerHealthCheck(),
+			"ah_k8s_cluster":                   resourceAHK8sCluster(),
 		},
 	}
 
@@ -67,8 +85,16 @@ func Provider() *schema.Provider {
 func providerConfigure(d *schema.ResourceData, terraformVersion string) (interface{}, error) {
 
 	config := Config{
-		Token:       d.Get("access_token").(string),
-		APIEndpoint: d.Get("endpoint").(string),
+		Token:                    d.Get("access_token").(string),
+		APIEndpoint:              d.Get("endpoint").(string),
+		ValidateReferencesAtPlan: d.Get("validate_references_at_plan").(bool),
+	}
+
+	// max_monthly_cost_increase = 0 allows no increase at all, GetOk can't tell it from unset
+	//nolint:staticcheck
+	if maxIncrease, ok := d.GetOkExists("max_monthly_cost_increase"); ok {
+		maxMonthlyCostIncrease := maxIncrease.(float64)
+		config.MaxMonthlyCostIncrease = &maxMonthlyCostIncrease
 	}
 
 	return config.Client()
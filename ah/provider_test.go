@@ -1,10 +1,17 @@
 package ah
 
 import (
+	"context"
+	"encoding/json"
 	"os"
+	"reflect"
 	"testing"
 
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/go-cty/cty"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 var (
@@ -26,3 +33,228 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("AH_ACCESS_TOKEN must be set for acceptance tests")
 	}
 }
+
+// fakeSSHKeysAPI serves a fixed list of SSH keys for unit tests.
+type fakeSSHKeysAPI struct {
+	ah.SSHKeysAPI
+	sshKeys []ah.SSHKey
+}
+
+func (f *fakeSSHKeysAPI) List(ctx context.Context, options *ah.ListOptions) ([]ah.SSHKey, *ah.Meta, error) {
+	return f.sshKeys, &ah.Meta{Page: 1, PerPage: len(f.sshKeys), Total: len(f.sshKeys)}, nil
+}
+
+// fakeInstancePlansAPI serves a fixed list of cloud server plans for unit tests.
+type fakeInstancePlansAPI struct {
+	ah.InstancePlansAPI
+	plans []ah.InstancePlan
+}
+
+func (f *fakeInstancePlansAPI) List(ctx context.Context) ([]ah.InstancePlan, error) {
+	return f.plans, nil
+}
+
+func testInstancePlan(id int, slug, vcpu, ram, disk, price string) ah.InstancePlan {
+	return ah.InstancePlan{
+		CustomAttributes: &ah.InstancePlanAttributes{Slug: slug, Vcpu: vcpu, RAM: ram, Disk: disk},
+		Plan: ah.Plan{
+			ID:       id,
+			Name:     slug,
+			Currency: "usd",
+			Prices:   map[int]ah.PlanPrice{1: {Type: "monthly,vps", Price: price, Currency: "usd"}},
+		},
+	}
+}
+
+func testFakeAPIClient() *ah.APIClient {
+	return &ah.APIClient{
+		SSHKeys: &fakeSSHKeysAPI{},
+		Backups: &fakeBackupsAPI{},
+		InstancePlans: &fakeInstancePlansAPI{
+			plans: []ah.InstancePlan{
+				testInstancePlan(1, "start-xs", "1", "1024", "20", "5.0"),
+				testInstancePlan(2, "start-m", "2", "2048", "40", "10.0"),
+				testInstancePlan(3, "cpu-m", "4", "2048", "40", "20.0"),
+				testInstancePlan(4, "ram-m", "1", "4096", "40", "20.0"),
+			},
+		},
+		Datacenters: &fakeDatacentersAPI{
+			datacenters: []ah.Datacenter{
+				{ID: DatacenterID, Slug: DatacenterName},
+				{ID: "0b9e4e5e-2a0b-4d1c-8b4f-0b2f0d7c1a11", Slug: "ash1"},
+			},
+		},
+		Images: &fakeImagesAPI{
+			images: []ah.Image{
+				{ID: "2c5e7b1a-6f2d-4c1e-9a3b-5d8f0e4c7b21", Slug: ImageName},
+				{ID: "8f1d3c2b-7a4e-4b6d-a5c9-3e2f1d0b9a87", Slug: "ubuntu-18_04-x64"},
+			},
+		},
+		VolumePlans: &fakeVolumePlansAPI{
+			plans: []ah.VolumePlan{
+				testVolumePlan(11, "hdd2-ash1", DatacenterID, "0.05"),
+				testVolumePlan(12, "ssd2-ash1", DatacenterID, "0.1"),
+			},
+		},
+	}
+}
+
+// fakeVolumePlansAPI serves a fixed list of volume plans for unit tests.
+type fakeVolumePlansAPI struct {
+	ah.VolumePlansAPI
+	plans []ah.VolumePlan
+}
+
+func (f *fakeVolumePlansAPI) List(ctx context.Context) ([]ah.VolumePlan, error) {
+	return f.plans, nil
+}
+
+func testVolumePlan(id int, slug, datacenterID, price string) ah.VolumePlan {
+	return ah.VolumePlan{
+		CustomAttributes: &ah.VolumePlanAttributes{Slug: slug, DatacenterIds: []string{datacenterID}},
+		Plan: ah.Plan{
+			ID:       id,
+			Name:     slug,
+			Currency: "usd",
+			Prices:   map[int]ah.PlanPrice{1: {Type: "overuse,volume_du", Price: price, Currency: "usd"}},
+		},
+	}
+}
+
+// fakeDatacentersAPI serves a fixed list of datacenters for unit tests.
+type fakeDatacentersAPI struct {
+	ah.DatacentersAPI
+	datacenters []ah.Datacenter
+}
+
+func (f *fakeDatacentersAPI) List(ctx context.Context, options *ah.ListOptions) ([]ah.Datacenter, error) {
+	return f.datacenters, nil
+}
+
+// fakeInstancesAPI serves a fixed list of cloud servers for unit tests.
+type fakeInstancesAPI struct {
+	ah.InstancesAPI
+	instances []ah.Instance
+}
+
+func (f *fakeInstancesAPI) Get(ctx context.Context, instanceID string) (*ah.Instance, error) {
+	for _, instance := range f.instances {
+		if instance.ID == instanceID {
+			return &instance, nil
+		}
+	}
+	return nil, ah.ErrResourceNotFound
+}
+
+// fakeBackupsAPI serves a list of snapshots and backups for unit tests and records deletions.
+type fakeBackupsAPI struct {
+	ah.BackupsAPI
+	backups []ah.Backup
+	deleted []string
+}
+
+func (f *fakeBackupsAPI) List(ctx context.Context, options *ah.ListOptions) ([]ah.InstanceBackups, error) {
+	var instancesBackups []ah.InstanceBackups
+	for _, backup := range f.backups {
+		instancesBackups = append(instancesBackups, ah.InstanceBackups{
+			InstanceID: backup.InstanceID,
+			Backups:    []ah.Backup{backup},
+		})
+	}
+	return instancesBackups, nil
+}
+
+func (f *fakeBackupsAPI) Delete(ctx context.Context, backupID string) (*ah.Action, error) {
+	for i, backup := range f.backups {
+		if backup.ID == backupID {
+			f.backups = append(f.backups[:i], f.backups[i+1:]...)
+			f.deleted = append(f.deleted, backupID)
+			return &ah.Action{}, nil
+		}
+	}
+	return nil, ah.ErrResourceNotFound
+}
+
+func (f *fakeBackupsAPI) Get(ctx context.Context, backupID string) (*ah.Backup, error) {
+	for _, backup := range f.backups {
+		if backup.ID == backupID {
+			return &backup, nil
+		}
+	}
+	return nil, ah.ErrResourceNotFound
+}
+
+// fakeImagesAPI serves a fixed list of images for unit tests.
+type fakeImagesAPI struct {
+	ah.ImagesAPI
+	images []ah.Image
+}
+
+func (f *fakeImagesAPI) List(ctx context.Context, options *ah.ListOptions) ([]ah.Image, *ah.Meta, error) {
+	return f.images, &ah.Meta{Page: 1, PerPage: len(f.images), Total: len(f.images)}, nil
+}
+
+func testFakeMeta() *CombinedConfig {
+	return &CombinedConfig{client: testFakeAPIClient()}
+}
+
+// testResourceConfig builds the raw configuration Terraform sends with a plan,
+// attributes missing from raw are null.
+func testResourceConfig(t *testing.T, r *schema.Resource, raw map[string]interface{}) cty.Value {
+	t.Helper()
+	b, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := ctyjson.Unmarshal(b, r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+// testResourceDiff plans the creation of r with raw configuration.
+func testResourceDiff(t *testing.T, r *schema.Resource, raw map[string]interface{}, meta interface{}) (*terraform.InstanceDiff, error) {
+	t.Helper()
+	state := &terraform.InstanceState{
+		RawConfig: testResourceConfig(t, r, raw),
+	}
+	return r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+}
+
+// testResourceUpdateDiff plans the update of an existing resource with attributes in state
+// to raw configuration.
+func testResourceUpdateDiff(t *testing.T, r *schema.Resource, attributes map[string]string, raw map[string]interface{}, meta interface{}) (*terraform.InstanceDiff, error) {
+	t.Helper()
+	state := &terraform.InstanceState{
+		ID:         attributes["id"],
+		Attributes: attributes,
+		RawConfig:  testResourceConfig(t, r, raw),
+	}
+	return r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+}
+
+func TestProviderConfigure_MaxMonthlyCostIncrease(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected *float64
+	}{
+		{"unset", map[string]interface{}{}, nil},
+		{"zero", map[string]interface{}{"max_monthly_cost_increase": 0.0}, new(float64)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.raw["access_token"] = "token"
+			d := schema.TestResourceDataRaw(t, Provider().Schema, tc.raw)
+			meta, err := providerConfigure(d, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := meta.(*CombinedConfig).maxMonthlyCostIncrease; !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected max_monthly_cost_increase %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// validateCloudServerReferences resolves datacenter, image, plan and ssh_keys of a cloud server
+// during plan when validate_references_at_plan is enabled, so wrong slugs fail before any
+// resource is created.
+func validateCloudServerReferences(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !meta.(*CombinedConfig).validateReferencesAtPlan {
+		return nil
+	}
+
+	changed := func(key string) bool {
+		return (d.Id() == "" || d.HasChange(key)) && d.NewValueKnown(key)
+	}
+
+	var invalid []string
+	if changed("datacenter") {
+		if err := validateDatacenterReference(ctx, meta, d.Get("datacenter").(string)); err != nil {
+			invalid = append(invalid, fmt.Sprintf("datacenter: %s", err))
+		}
+	}
+	if changed("image") {
+		if image, ok := d.GetOk("image"); ok {
+			if err := validateImageReference(ctx, meta, image.(string)); err != nil {
+				invalid = append(invalid, fmt.Sprintf("image: %s", err))
+			}
+		}
+	}
+	if changed("plan") && !d.Get("private_cloud").(bool) {
+		if plan, ok := d.GetOk("plan"); ok {
+			if err := validatePlanReference(meta, plan.(string)); err != nil {
+				invalid = append(invalid, fmt.Sprintf("plan: %s", err))
+			}
+		}
+	}
+	if changed("ssh_keys") {
+		for i, v := range d.Get("ssh_keys").([]interface{}) {
+			if err := validateSSHKeyReference(v.(string), meta); err != nil {
+				invalid = append(invalid, fmt.Sprintf("ssh_keys.%d: %s", i, err))
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid cloud server references: %s", strings.Join(invalid, "; "))
+	}
+	return nil
+}
+
+func validateDatacenterReference(ctx context.Context, meta interface{}, datacenterAttr string) error {
+	datacenters, err := meta.(*CombinedConfig).datacenters(ctx)
+	if err != nil {
+		return err
+	}
+	slugs := make([]string, len(datacenters))
+	for i, datacenter := range datacenters {
+		if datacenter.ID == datacenterAttr || datacenter.Slug == datacenterAttr {
+			return nil
+		}
+		slugs[i] = datacenter.Slug
+	}
+	return referenceNotFoundError("datacenter", datacenterAttr, slugs)
+}
+
+// validateImageReference accepts image IDs and slugs, and IDs of snapshots and backups,
+// which aren't part of the image list.
+func validateImageReference(ctx context.Context, meta interface{}, imageAttr string) error {
+	images, err := meta.(*CombinedConfig).images()
+	if err != nil {
+		return err
+	}
+	slugs := make([]string, 0, len(images))
+	for _, image := range images {
+		if image.ID == imageAttr || image.Slug == imageAttr {
+			return nil
+		}
+		if image.Slug != "" {
+			slugs = append(slugs, image.Slug)
+		}
+	}
+
+	if IsUUID(imageAttr) {
+		client := meta.(*CombinedConfig).ahClient()
+		if _, err := client.Backups.Get(ctx, imageAttr); err == nil {
+			return nil
+		} else if err != ah.ErrResourceNotFound {
+			return err
+		}
+	}
+	return referenceNotFoundError("image", imageAttr, slugs)
+}
+
+func validatePlanReference(meta interface{}, planAttr string) error {
+	if _, err := cloudServerPlanByAttr(meta, planAttr); err == nil {
+		return nil
+	}
+
+	cloudServerPlans, err := allCloudServerPlans(meta)
+	if err != nil {
+		return err
+	}
+	slugs := make([]string, len(cloudServerPlans))
+	for i, cloudServerPlan := range cloudServerPlans {
+		slugs[i] = cloudServerPlan.CustomAttributes.Slug
+	}
+	return referenceNotFoundError("cloud server plan", planAttr, slugs)
+}
+
+func validateSSHKeyReference(sshKeyAttr string, meta interface{}) error {
+	if IsUUID(sshKeyAttr) {
+		sshKeys, err := meta.(*CombinedConfig).sshKeys()
+		if err != nil {
+			return err
+		}
+		for _, sshKey := range sshKeys {
+			if sshKey.ID == sshKeyAttr {
+				return nil
+			}
+		}
+		return fmt.Errorf("ssh key %q not found", sshKeyAttr)
+	}
+	if _, err := sshKeyByFingerprint(sshKeyAttr, meta); err == nil {
+		return nil
+	} else if err != ah.ErrResourceNotFound {
+		return err
+	}
+
+	sshKeys, err := meta.(*CombinedConfig).sshKeys()
+	if err != nil {
+		return err
+	}
+	fingerprints := make([]string, len(sshKeys))
+	for i, sshKey := range sshKeys {
+		fingerprints[i] = sshKey.Fingerprint
+	}
+	return referenceNotFoundError("ssh key fingerprint", sshKeyAttr, fingerprints)
+}
+
+func referenceNotFoundError(kind, value string, candidates []string) error {
+	if matches := closeMatches(value, candidates); len(matches) > 0 {
+		return fmt.Errorf("%s %q not found, did you mean %s?", kind, value, strings.Join(quoteAll(matches), " or "))
+	}
+	return fmt.Errorf("%s %q not found", kind, value)
+}
+
+// closeMatches returns up to three candidates ordered by their edit distance to value.
+func closeMatches(value string, candidates []string) []string {
+	maxDistance := len(value) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	type match struct {
+		candidate string
+		distance  int
+	}
+	var matches []match
+	seen := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		distance := levenshteinDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if distance <= maxDistance {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	var result []string
+	for i := 0; i < len(matches) && i < 3; i++ {
+		result = append(result, matches[i].candidate)
+	}
+	return result
+}
+
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
@@ -0,0 +1,98 @@
+package ah
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+)
+
+func TestValidateCloudServerReferences(t *testing.T) {
+	fingerprint := "7e:ac:a8:45:83:e3:58:f5:3a:9f:dd:16:63:dc:fb:1e"
+	sshKeyID := "5a1f0c3e-9b2d-4e7a-8c6f-1d3b5e7a9c20"
+	snapshotID := "3d6b9e2f-1c4a-4f8e-b7d0-6a2c8e4f1b93"
+	unknownID := "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b"
+	config := func(attrs map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"name":       "test",
+			"datacenter": DatacenterName,
+			"image":      ImageName,
+			"plan":       "start-xs",
+			"ssh_keys":   []interface{}{fingerprint},
+		}
+		for k, v := range attrs {
+			c[k] = v
+		}
+		return c
+	}
+
+	cases := []struct {
+		name        string
+		config      map[string]interface{}
+		expectedErr string
+	}{
+		{"valid references", config(nil), ""},
+		{"datacenter ID", config(map[string]interface{}{"datacenter": DatacenterID}), ""},
+		{"plan ID", config(map[string]interface{}{"plan": "2"}), ""},
+		{"snapshot ID", config(map[string]interface{}{"image": snapshotID}), ""},
+		{"ssh key ID", config(map[string]interface{}{"ssh_keys": []interface{}{sshKeyID}}), ""},
+		{"unknown datacenter ID", config(map[string]interface{}{"datacenter": unknownID}), `datacenter: datacenter "` + unknownID + `" not found`},
+		{"unknown image ID", config(map[string]interface{}{"image": unknownID}), `image: image "` + unknownID + `" not found`},
+		{"unknown ssh key ID", config(map[string]interface{}{"ssh_keys": []interface{}{unknownID}}), `ssh_keys.0: ssh key "` + unknownID + `" not found`},
+		{"datacenter typo", config(map[string]interface{}{"datacenter": "asm1"}), `datacenter: datacenter "asm1" not found, did you mean "ash1" or "ams1"?`},
+		{"unknown datacenter", config(map[string]interface{}{"datacenter": "lon-central"}), `datacenter: datacenter "lon-central" not found`},
+		{"image typo", config(map[string]interface{}{"image": "centos-7-x86"}), `image: image "centos-7-x86" not found, did you mean "centos-7-x64"?`},
+		{"ssh key typo", config(map[string]interface{}{"ssh_keys": []interface{}{"7e:ac:a8:45:83:e3:58:f5:3a:9f:dd:16:63:dc:fb:1f"}}),
+			`ssh_keys.0: ssh key fingerprint "7e:ac:a8:45:83:e3:58:f5:3a:9f:dd:16:63:dc:fb:1f" not found, did you mean "` + fingerprint + `"?`},
+		{"several references", config(map[string]interface{}{"datacenter": "asm1", "image": "centos-7-x86"}), `datacenter: datacenter "asm1" not found, did you mean "ash1" or "ams1"?; image:`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			meta := testFakeMeta()
+			meta.validateReferencesAtPlan = true
+			meta.client.SSHKeys = &fakeSSHKeysAPI{sshKeys: []ah.SSHKey{{ID: sshKeyID, Fingerprint: fingerprint}}}
+			meta.client.Backups = &fakeBackupsAPI{backups: []ah.Backup{{ID: snapshotID, Type: "snapshot", Status: "ready"}}}
+
+			_, err := testResourceDiff(t, resourceAHCloudServer(), tc.config, meta)
+			if tc.expectedErr == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedErr)) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateCloudServerReferences_Disabled(t *testing.T) {
+	config := map[string]interface{}{
+		"name":       "test",
+		"datacenter": "asm1",
+		"image":      "centos-7-x86",
+		"plan":       "start-xs",
+	}
+	if _, err := testResourceDiff(t, resourceAHCloudServer(), config, testFakeMeta()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestCloseMatches(t *testing.T) {
+	candidates := []string{"start-xs", "start-s", "start-m", "start-l", "cpu-m"}
+	cases := []struct {
+		value    string
+		expected []string
+	}{
+		{"start-xs", []string{"start-xs", "start-s", "start-m"}},
+		{"Start-M", []string{"start-m", "start-s", "start-l"}},
+		{"strat-xs", []string{"start-xs"}},
+		{"ram-xxl", nil},
+	}
+
+	for _, tc := range cases {
+		if matches := closeMatches(tc.value, candidates); !reflect.DeepEqual(matches, tc.expected) {
+			t.Errorf("closeMatches(%q): expected %v, got %v", tc.value, tc.expected, matches)
+		}
+	}
+}
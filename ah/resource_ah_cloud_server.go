@@ -6,17 +6,19 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/advancedhosting/advancedhosting-api-go/ah"
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceAHCloudServer() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceAHCloudServerCreate,
 		ReadContext:   resourceAHCloudServerRead,
 		UpdateContext: resourceAHCloudServerUpdate,
@@ -24,6 +26,7 @@ func resourceAHCloudServer() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceAHCloudServerImport,
 		},
+		SchemaVersion: 1,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -39,9 +42,17 @@ func resourceAHCloudServer() *schema.Resource {
 			},
 			"image": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
 				ForceNew:     true,
 				ValidateFunc: validation.NoZeroValues,
+				ExactlyOneOf: []string{"image", "source_snapshot_id"},
+			},
+			"source_snapshot_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+				ExactlyOneOf: []string{"image", "source_snapshot_id"},
 			},
 			"product": {
 				Type:       schema.TypeString,
@@ -62,6 +73,11 @@ func resourceAHCloudServer() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			// monthly_cost is set by the plan so it shows the cost of new resources and plan changes
+			"monthly_cost": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
 			"ssh_keys": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -101,9 +117,32 @@ func resourceAHCloudServer() *schema.Resource {
 				},
 			},
 			"backups": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Computed:      true,
+				Deprecated:    "use backup_policy instead",
+				ConflictsWith: []string{"backup_policy"},
+			},
+			"backup_policy": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Computed:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"backups"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Required: true,
+						},
+						"period": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "weekly",
+							ValidateFunc: validation.StringInSlice([]string{"daily", "weekly", "monthly"}, false),
+						},
+					},
+				},
 			},
 			"private_cloud": {
 				Type:     schema.TypeBool,
@@ -111,14 +150,20 @@ func resourceAHCloudServer() *schema.Resource {
 				Default:  false,
 			},
 			"node_id": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  false,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.NoZeroValues,
 			},
 			"cluster_id": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  false,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"network_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
 			},
 			"state": {
 				Type:     schema.TypeString,
@@ -140,11 +185,75 @@ func resourceAHCloudServer() *schema.Resource {
 				Computed: true,
 			},
 		},
+		CustomizeDiff: customdiff.All(
+			customdiff.ValidateChange("backup_policy", func(ctx context.Context, old, new, meta interface{}) error {
+				if len(old.([]interface{})) == 0 || backupPolicyEqual(old.([]interface{}), new.([]interface{})) {
+					return nil
+				}
+				return fmt.Errorf("backup_policy of an existing cloud server can't be changed through the API, " +
+					"change the backup schedule in the panel or recreate the server")
+			}),
+			validateBackupsChange,
+			validatePrivateCloudArguments,
+			validateCloudServerReferences,
+			validatePrivateCloudResize,
+			validatePrivateCloudPlacement,
+			validatePlanDowngrade,
+			validateSourceSnapshot,
+			cloudServerCostDiff,
+		),
 	}
+
+	// Version 0 had the same attribute types, only the stored values differ
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: resourceAHCloudServerStateUpgradeV0,
+		},
+	}
+	return r
+}
+
+// resourceAHCloudServerStateUpgradeV0 drops the "false" that node_id and cluster_id used to
+// default to, so servers stored by earlier versions don't plan a change to null.
+func resourceAHCloudServerStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	for _, key := range []string{"node_id", "cluster_id"} {
+		if rawState[key] == "false" {
+			delete(rawState, key)
+		}
+	}
+	return rawState, nil
 }
 
 func resourceAHCloudServerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
+
+	request, err := expandInstanceCreateRequest(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	instance, err := client.Instances.Create(ctx, request)
+
+	if err != nil {
+		return diag.Errorf("Error creating instance: %s", err)
+	}
+
+	d.SetId(instance.ID)
+	if err = waitForStatus([]string{"creating", "stopped"}, []string{"running"}, d, meta); err != nil {
+		return diag.Errorf(
+			"Error waiting for cloud server (%s) to become ready: %s", d.Id(), err)
+	}
+
+	//Wait for instance to completely load
+	time.Sleep(20 * time.Second)
+
+	return resourceAHCloudServerRead(ctx, d, meta)
+
+}
+
+func expandInstanceCreateRequest(d *schema.ResourceData, meta interface{}) (*ah.InstanceCreateRequest, error) {
 	request := &ah.InstanceCreateRequest{
 		Name:                  d.Get("name").(string),
 		CreatePublicIPAddress: d.Get("create_public_ip_address").(bool),
@@ -158,16 +267,19 @@ func resourceAHCloudServerCreate(ctx context.Context, d *schema.ResourceData, me
 		request.DatacenterID = datacenterAttr
 	}
 
-	imageAttr := d.Get("image").(string)
-	if _, err := uuid.Parse(imageAttr); err != nil {
-		request.ImageSlug = imageAttr
+	if attr, ok := d.GetOk("source_snapshot_id"); ok {
+		request.ImageID = attr.(string)
 	} else {
-		request.ImageID = imageAttr
+		imageAttr := d.Get("image").(string)
+		if _, err := uuid.Parse(imageAttr); err != nil {
+			request.ImageSlug = imageAttr
+		} else {
+			request.ImageID = imageAttr
+		}
 	}
 
-	privateCloud, privateCloudOk := d.GetOk("private_cloud")
-	if privateCloudOk {
-		request.PrivateCloud = privateCloud.(bool)
+	if d.Get("private_cloud").(bool) {
+		request.PrivateCloud = true
 		request.ClusterID = d.Get("cluster_id").(string)
 		request.NodeID = d.Get("node_id").(string)
 		networkID, networkIDOk := d.GetOk("network_id")
@@ -182,7 +294,7 @@ func resourceAHCloudServerCreate(ctx context.Context, d *schema.ResourceData, me
 		plan, planOk := d.GetOk("plan")
 		product, productOk := d.GetOk("product")
 		if !planOk && !productOk {
-			return diag.Errorf("one of plan or product must be configured")
+			return nil, fmt.Errorf("one of plan or product must be configured")
 		}
 
 		if planOk {
@@ -210,7 +322,7 @@ func resourceAHCloudServerCreate(ctx context.Context, d *schema.ResourceData, me
 			} else {
 				sshKey, err := sshKeyByFingerprint(v.(string), meta)
 				if err != nil {
-					return diag.Errorf("Error searching ssh key by fingerprint %s: %v", v.(string), err)
+					return nil, fmt.Errorf("Error searching ssh key by fingerprint %s: %v", v.(string), err)
 				}
 				sshKeyIDs = append(sshKeyIDs, sshKey.ID)
 
@@ -220,42 +332,40 @@ func resourceAHCloudServerCreate(ctx context.Context, d *schema.ResourceData, me
 		request.SSHKeyIDs = sshKeyIDs
 	}
 
-	if attr, ok := d.GetOk("backups"); ok {
+	if attr, ok := d.GetOk("backup_policy"); ok {
+		policy := attr.([]interface{})[0].(map[string]interface{})
+		if policy["enabled"].(bool) {
+			request.SnapshotBySchedule = true
+			request.SnapshotPeriod = policy["period"].(string)
+		}
+	} else if attr, ok := d.GetOk("backups"); ok {
 		if attr.(bool) {
 			request.SnapshotBySchedule = true
 			request.SnapshotPeriod = "weekly"
 		}
 	}
 
-	instance, err := client.Instances.Create(ctx, request)
-
-	if err != nil {
-		return diag.Errorf("Error creating instance: %s", err)
-	}
-
-	d.SetId(instance.ID)
-	if err = waitForStatus([]string{"creating", "stopped"}, []string{"running"}, d, meta); err != nil {
-		return diag.Errorf(
-			"Error waiting for cloud server (%s) to become ready: %s", d.Id(), err)
-	}
-
-	//Wait for instance to completely load
-	time.Sleep(20 * time.Second)
-
-	return resourceAHCloudServerRead(ctx, d, meta)
-
+	return request, nil
 }
 
 func resourceAHCloudServerImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	instance, err := client.Instances.Get(ctx, d.Id())
 	if err != nil {
 		return nil, err
 	}
-	if instance.Image.Slug != "" {
-		d.Set("image", instance.Image.Slug)
-	} else {
-		d.Set("image", instance.Image.ID)
+	// Servers created from a snapshot report it as an image without a slug, those
+	// are imported with source_snapshot_id so the configuration doesn't plan a replacement
+	if instance.Image != nil && instance.Image.Image != nil {
+		if instance.Image.Slug != "" {
+			d.Set("image", instance.Image.Slug)
+		} else if _, err := client.Backups.Get(ctx, instance.Image.ID); err == nil {
+			d.Set("source_snapshot_id", instance.Image.ID)
+		} else if err == ah.ErrResourceNotFound {
+			d.Set("image", instance.Image.ID)
+		} else {
+			return nil, err
+		}
 	}
 
 	if instance.Datacenter.Slug != "" {
@@ -277,7 +387,7 @@ func resourceAHCloudServerImport(ctx context.Context, d *schema.ResourceData, me
 }
 
 func resourceAHCloudServerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	instance, err := client.Instances.Get(context.Background(), d.Id())
 	if err != nil {
 		return diag.FromErr(err)
@@ -290,6 +400,7 @@ func resourceAHCloudServerRead(ctx context.Context, d *schema.ResourceData, meta
 	d.Set("ram", instance.RAM)
 	d.Set("disk", instance.Disk)
 	d.Set("backups", instance.SnapshotBySchedule)
+	d.Set("backup_policy", flattenBackupPolicy(d, instance))
 	d.Set("use_password", instance.UseSSHPassword)
 
 	var ips []map[string]interface{}
@@ -317,7 +428,7 @@ func resourceAHCloudServerUpdate(ctx context.Context, d *schema.ResourceData, me
 
 	if d.HasChange("name") {
 		newName := d.Get("name").(string)
-		client := meta.(*ah.APIClient)
+		client := meta.(*CombinedConfig).ahClient()
 
 		_, err := client.Instances.Rename(ctx, d.Id(), newName)
 
@@ -327,8 +438,21 @@ func resourceAHCloudServerUpdate(ctx context.Context, d *schema.ResourceData, me
 		}
 	}
 
+	var diags diag.Diagnostics
+
 	if d.HasChange("plan") {
-		client := meta.(*ah.APIClient)
+		client := meta.(*CombinedConfig).ahClient()
+
+		oldPlanAttr, newPlanAttr := d.GetChange("plan")
+		if oldPlan, newPlan, ok := cloudServerPlanChange(d.Id(), meta, oldPlanAttr.(string), newPlanAttr.(string)); ok {
+			if warning := planDowngradeWarning(oldPlan, newPlan); warning != "" {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  "Cloud server plan downgrade",
+					Detail:   warning,
+				})
+			}
+		}
 
 		request := &ah.InstanceUpgradeRequest{}
 
@@ -349,11 +473,11 @@ func resourceAHCloudServerUpdate(ctx context.Context, d *schema.ResourceData, me
 		}
 	}
 
-	return resourceAHCloudServerRead(ctx, d, meta)
+	return append(diags, resourceAHCloudServerRead(ctx, d, meta)...)
 }
 
 func resourceAHCloudServerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.Instances.PowerOff(ctx, d.Id()); err != nil {
 		return diag.Errorf(
 			"Error power_off instance (%s): %s", d.Id(), err)
@@ -378,7 +502,7 @@ func resourceAHCloudServerDelete(ctx context.Context, d *schema.ResourceData, me
 }
 
 func waitForStatus(pendingStatuses, targetStatuses []string, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		instance, err := client.Instances.Get(context.Background(), d.Id())
@@ -408,7 +532,7 @@ func waitForStatus(pendingStatuses, targetStatuses []string, d *schema.ResourceD
 }
 
 func waitForDestroy(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		instance, err := client.Instances.Get(context.Background(), d.Id())
@@ -442,8 +566,7 @@ func waitForDestroy(d *schema.ResourceData, meta interface{}) error {
 }
 
 func sshKeyByFingerprint(fingerprint string, meta interface{}) (*ah.SSHKey, error) {
-	client := meta.(*ah.APIClient)
-	sshKeys, err := allSSHKeysInfo(client, &ah.ListOptions{})
+	sshKeys, err := meta.(*CombinedConfig).sshKeys()
 	if err != nil {
 		return nil, err
 	}
@@ -455,3 +578,248 @@ func sshKeyByFingerprint(fingerprint string, meta interface{}) (*ah.SSHKey, erro
 	}
 	return nil, ah.ErrResourceNotFound
 }
+
+// validatePrivateCloudArguments checks the configuration rather than the planned values,
+// because vcpu, ram and disk are computed for public cloud servers.
+func validatePrivateCloudArguments(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	config := d.GetRawConfig()
+	if config.IsNull() {
+		return nil
+	}
+
+	isSet := func(key string) bool {
+		return !config.GetAttr(key).IsNull()
+	}
+
+	var invalid []string
+	if d.Get("private_cloud").(bool) {
+		for _, key := range []string{"cluster_id", "node_id", "vcpu", "ram", "disk"} {
+			if !isSet(key) {
+				invalid = append(invalid, fmt.Sprintf("%s is required when private_cloud is true", key))
+			}
+		}
+		for _, key := range []string{"plan", "product"} {
+			if isSet(key) {
+				invalid = append(invalid, fmt.Sprintf("%s can't be set when private_cloud is true", key))
+			}
+		}
+	} else {
+		for _, key := range []string{"cluster_id", "node_id", "network_id", "vcpu", "ram", "disk"} {
+			if isSet(key) {
+				invalid = append(invalid, fmt.Sprintf("%s can only be set when private_cloud is true", key))
+			}
+		}
+		if !isSet("plan") && !isSet("product") {
+			invalid = append(invalid, "one of plan or product must be configured")
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid cloud server arguments: %s", strings.Join(invalid, "; "))
+	}
+	return nil
+}
+
+// validatePrivateCloudResize rejects changes of private cloud server resources which
+// would otherwise be ignored, the upgrade request of the API only accepts plans.
+func validatePrivateCloudResize(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.Get("private_cloud").(bool) {
+		return nil
+	}
+
+	if d.HasChange("disk") && d.NewValueKnown("disk") {
+		oldDisk, newDisk := d.GetChange("disk")
+		if newDisk.(int) < oldDisk.(int) {
+			return fmt.Errorf("disk of cloud server %s can't be shrunk from %d GB to %d GB", d.Id(), oldDisk.(int), newDisk.(int))
+		}
+	}
+
+	for _, key := range []string{"vcpu", "ram", "disk"} {
+		if d.HasChange(key) {
+			return fmt.Errorf("%s of private cloud server %s can't be changed in place, "+
+				"the API only supports resizing through plans", key, d.Id())
+		}
+	}
+	return nil
+}
+
+// validatePrivateCloudPlacement rejects moving a private cloud server to another node or cluster,
+// the API has no migrate action yet.
+func validatePrivateCloudPlacement(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.Get("private_cloud").(bool) {
+		return nil
+	}
+
+	for _, key := range []string{"cluster_id", "node_id"} {
+		if d.HasChange(key) {
+			return fmt.Errorf("%s of private cloud server %s can't be changed, migration between nodes is not supported by the API", key, d.Id())
+		}
+	}
+	return nil
+}
+
+// validatePlanDowngrade rejects plan changes that would shrink the disk, which the API
+// only refuses once the apply has started, and warns about vCPU and RAM reductions.
+func validatePlanDowngrade(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange("plan") || !d.NewValueKnown("plan") {
+		return nil
+	}
+
+	oldPlanAttr, newPlanAttr := d.GetChange("plan")
+	oldPlan, newPlan, ok := cloudServerPlanChange(d.Id(), meta, oldPlanAttr.(string), newPlanAttr.(string))
+	if !ok {
+		// Without both plans the new resources are unknown until the upgrade is done
+		for _, key := range []string{"vcpu", "ram", "disk"} {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if warning := planDowngradeWarning(oldPlan, newPlan); warning != "" {
+		log.Printf("[WARN] cloud server %s: %s", d.Id(), warning)
+	}
+
+	oldVcpu, oldRAM, oldDisk := cloudServerPlanSpecs(oldPlan)
+	newVcpu, newRAM, newDisk := cloudServerPlanSpecs(newPlan)
+	if newDisk < oldDisk {
+		return fmt.Errorf("plan of cloud server %s can't be changed from %s to %s, the disk can't be shrunk",
+			d.Id(), describeCloudServerPlan(oldPlan), describeCloudServerPlan(newPlan))
+	}
+
+	specs := []struct {
+		key      string
+		old, new int
+	}{{"vcpu", oldVcpu, newVcpu}, {"ram", oldRAM, newRAM}, {"disk", oldDisk, newDisk}}
+	for _, spec := range specs {
+		if spec.old != spec.new {
+			if err := d.SetNewComputed(spec.key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cloudServerPlanChange resolves the old and new plan of a plan change. Retired plans are
+// missing from the plan list, the downgrade checks are skipped for them rather than blocking
+// the change.
+func cloudServerPlanChange(id string, meta interface{}, oldPlanAttr, newPlanAttr string) (*ah.InstancePlan, *ah.InstancePlan, bool) {
+	if oldPlanAttr == "" || newPlanAttr == "" {
+		return nil, nil, false
+	}
+	oldPlan, err := cloudServerPlanByAttr(meta, oldPlanAttr)
+	if err != nil {
+		log.Printf("[DEBUG] skipping plan downgrade checks of cloud server %s: %s", id, err)
+		return nil, nil, false
+	}
+	newPlan, err := cloudServerPlanByAttr(meta, newPlanAttr)
+	if err != nil {
+		log.Printf("[DEBUG] skipping plan downgrade checks of cloud server %s: %s", id, err)
+		return nil, nil, false
+	}
+	return oldPlan, newPlan, true
+}
+
+func planDowngradeWarning(oldPlan, newPlan *ah.InstancePlan) string {
+	oldVcpu, oldRAM, _ := cloudServerPlanSpecs(oldPlan)
+	newVcpu, newRAM, _ := cloudServerPlanSpecs(newPlan)
+	if newVcpu >= oldVcpu && newRAM >= oldRAM {
+		return ""
+	}
+	return fmt.Sprintf("changing plan from %s to %s reduces the vCPU or RAM of the server",
+		describeCloudServerPlan(oldPlan), describeCloudServerPlan(newPlan))
+}
+
+// validateSourceSnapshot checks the snapshot of new servers, and of existing ones when a new
+// snapshot replaces them.
+func validateSourceSnapshot(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if (d.Id() != "" && !d.HasChange("source_snapshot_id")) || !d.NewValueKnown("source_snapshot_id") {
+		return nil
+	}
+	snapshotID, ok := d.GetOk("source_snapshot_id")
+	if !ok {
+		return nil
+	}
+
+	client := meta.(*CombinedConfig).ahClient()
+	backup, err := client.Backups.Get(ctx, snapshotID.(string))
+	if err != nil {
+		return fmt.Errorf("Error getting snapshot %s: %s", snapshotID, err)
+	}
+	if backup.Status != "ready" {
+		return fmt.Errorf("snapshot %s is %s, only ready snapshots can be used to create a cloud server", backup.ID, backup.Status)
+	}
+
+	var disk int
+	if d.Get("private_cloud").(bool) {
+		if !d.NewValueKnown("disk") {
+			return nil
+		}
+		disk = d.Get("disk").(int)
+	} else {
+		planAttr, ok := d.GetOk("plan")
+		if !ok {
+			planAttr, ok = d.GetOk("product")
+		}
+		if !ok || !d.NewValueKnown("plan") || !d.NewValueKnown("product") {
+			return nil
+		}
+		plan, err := cloudServerPlanByAttr(meta, planAttr.(string))
+		if err != nil {
+			return err
+		}
+		_, _, disk = cloudServerPlanSpecs(plan)
+	}
+
+	if disk < backup.MinDiskSize {
+		return fmt.Errorf("snapshot %s requires a disk of at least %d GB, the requested disk is %d GB", backup.ID, backup.MinDiskSize, disk)
+	}
+	return nil
+}
+
+func flattenBackupPolicy(d *schema.ResourceData, instance *ah.Instance) []map[string]interface{} {
+	period := instance.SnapshotPeriod
+	if period == "" {
+		// The API doesn't return a period for servers without scheduled backups
+		period = d.Get("backup_policy.0.period").(string)
+	}
+	if period == "" {
+		period = "weekly"
+	}
+	return []map[string]interface{}{
+		{
+			"enabled": instance.SnapshotBySchedule,
+			"period":  period,
+		},
+	}
+}
+
+// validateBackupsChange rejects toggling the deprecated backups argument of an existing server,
+// which the API can't change either.
+func validateBackupsChange(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange("backups") {
+		return nil
+	}
+	return fmt.Errorf("backups of an existing cloud server can't be changed through the API, " +
+		"change the backup schedule in the panel or recreate the server")
+}
+
+func backupPolicyEqual(old, new []interface{}) bool {
+	if len(old) != len(new) {
+		return false
+	}
+	for i := range old {
+		oldPolicy := old[i].(map[string]interface{})
+		newPolicy := new[i].(map[string]interface{})
+		if oldPolicy["enabled"].(bool) != newPolicy["enabled"].(bool) {
+			return false
+		}
+		// The period is meaningless while backups are disabled
+		if newPolicy["enabled"].(bool) && oldPolicy["period"].(string) != newPolicy["period"].(string) {
+			return false
+		}
+	}
+	return true
+}
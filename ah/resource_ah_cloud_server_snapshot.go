@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -48,12 +49,40 @@ func resourceAHCloudServerSnapshot() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"expire_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+			"expires_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
+		CustomizeDiff: customdiff.All(
+			func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+				if d.Id() == "" {
+					return nil
+				}
+				// Read computes expires_at from the new expire_after
+				if d.HasChange("expire_after") {
+					return d.SetNewComputed("expires_at")
+				}
+				// Expired snapshots are replaced, which deletes the old one
+				if !snapshotExpired(d.Get("expires_at").(string)) {
+					return nil
+				}
+				if err := d.SetNewComputed("expires_at"); err != nil {
+					return err
+				}
+				return d.ForceNew("expires_at")
+			},
+		),
 	}
 }
 
 func resourceAHCloudServerSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	instanceID := d.Get("cloud_server_id").(string)
 
@@ -87,6 +116,12 @@ func resourceAHCloudServerSnapshotCreate(d *schema.ResourceData, meta interface{
 
 func resourceAHCloudServerSnapshotRead(d *schema.ResourceData, meta interface{}) error {
 	backup, instanceName, err := snapshotInfo(d, meta)
+	if err == ah.ErrResourceNotFound {
+		// The snapshot has been pruned or expired outside of this resource
+		log.Printf("[WARN] Snapshot (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -99,11 +134,43 @@ func resourceAHCloudServerSnapshotRead(d *schema.ResourceData, meta interface{})
 	d.Set("type", backup.Type)
 	d.Set("created_at", backup.CreatedAt)
 
+	expiresAt, err := snapshotExpiresAt(backup.CreatedAt, d.Get("expire_after").(string))
+	if err != nil {
+		return err
+	}
+	d.Set("expires_at", expiresAt)
+
 	return nil
 }
 
+func snapshotExpiresAt(createdAt, expireAfter string) (string, error) {
+	if expireAfter == "" {
+		return "", nil
+	}
+	created, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing snapshot creation time %s: %s", createdAt, err)
+	}
+	duration, err := time.ParseDuration(expireAfter)
+	if err != nil {
+		return "", err
+	}
+	return created.Add(duration).Format(time.RFC3339), nil
+}
+
+func snapshotExpired(expiresAt string) bool {
+	if expiresAt == "" {
+		return false
+	}
+	expires, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return false
+	}
+	return time.Now().After(expires)
+}
+
 func snapshotInfo(d *schema.ResourceData, meta interface{}) (*ah.Backup, string, error) {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	backup, err := client.Backups.Get(context.Background(), d.Id())
 
 	if err != nil {
@@ -114,7 +181,7 @@ func snapshotInfo(d *schema.ResourceData, meta interface{}) (*ah.Backup, string,
 }
 
 func resourceAHCloudServerSnapshotUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	if d.HasChange("name") {
 
@@ -132,12 +199,12 @@ func resourceAHCloudServerSnapshotUpdate(d *schema.ResourceData, meta interface{
 }
 
 func resourceAHCloudServerSnapshotDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if _, err := client.Backups.Delete(context.Background(), d.Id()); err != nil {
 		return fmt.Errorf(
 			"Error deleting backup (%s): %s", d.Id(), err)
 	}
-	if err := waitForBackupDestroy(d, meta); err != nil {
+	if err := waitForBackupDestroy(d.Id(), d, meta); err != nil {
 		return fmt.Errorf(
 			"Error waiting for backup (%s) to become destroyed: %s", d.Id(), err)
 	}
@@ -145,7 +212,7 @@ func resourceAHCloudServerSnapshotDelete(d *schema.ResourceData, meta interface{
 }
 
 func waitForBackupReady(instanceID, actionID string, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		action, err := client.Instances.ActionInfo(context.Background(), instanceID, actionID)
@@ -175,13 +242,16 @@ func waitForBackupReady(instanceID, actionID string, d *schema.ResourceData, met
 
 }
 
-func waitForBackupDestroy(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+// backupDestroyDelay is the time to wait before checking a deleted backup, unit tests shorten it
+var backupDestroyDelay = 5 * time.Second
+
+func waitForBackupDestroy(backupID string, d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
-		backup, err := client.Backups.Get(context.Background(), d.Id())
+		backup, err := client.Backups.Get(context.Background(), backupID)
 		if err == ah.ErrResourceNotFound {
-			return d.Id(), "deleted", nil
+			return backupID, "deleted", nil
 		}
 		if err != nil {
 			log.Printf("Error on waitForBackupDestroy: %v", err)
@@ -192,7 +262,7 @@ func waitForBackupDestroy(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	stateChangeConf := resource.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      backupDestroyDelay,
 		Pending:    []string{"pending_delete"},
 		Refresh:    stateRefreshFunc,
 		Target:     []string{"deleted"},
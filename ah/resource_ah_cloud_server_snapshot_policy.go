@@ -0,0 +1,167 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceAHCloudServerSnapshotPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAHCloudServerSnapshotPolicyCreate,
+		ReadContext:   resourceAHCloudServerSnapshotPolicyRead,
+		UpdateContext: resourceAHCloudServerSnapshotPolicyUpdate,
+		DeleteContext: resourceAHCloudServerSnapshotPolicyDelete,
+
+		Schema: map[string]*schema.Schema{
+			"cloud_server_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"keep": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			// name_prefix is required so the policy never deletes snapshots managed by
+			// ah_cloud_server_snapshot, which would recreate them on every apply
+			"name_prefix": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			// snapshots_to_prune is only set in plans, state keeps it empty so that every plan
+			// with snapshots to delete differs from state and triggers an apply
+			"snapshots_to_prune": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+			if !d.NewValueKnown("cloud_server_id") || !d.NewValueKnown("keep") || !d.NewValueKnown("name_prefix") {
+				return nil
+			}
+			snapshotIDs, err := snapshotsToPrune(ctx, meta, d.Get("cloud_server_id").(string), d.Get("keep").(int), d.Get("name_prefix").(string))
+			if err != nil {
+				return err
+			}
+			if len(snapshotIDs) == 0 {
+				return nil
+			}
+			return d.SetNew("snapshots_to_prune", snapshotIDs)
+		},
+	}
+}
+
+func resourceAHCloudServerSnapshotPolicyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(d.Get("cloud_server_id").(string))
+
+	if err := pruneSnapshots(ctx, d, meta); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceAHCloudServerSnapshotPolicyRead(ctx, d, meta)
+}
+
+func resourceAHCloudServerSnapshotPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*CombinedConfig).ahClient()
+	if _, err := client.Instances.Get(ctx, d.Id()); err != nil {
+		if err == ah.ErrResourceNotFound {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	d.Set("cloud_server_id", d.Id())
+	d.Set("snapshots_to_prune", []string{})
+
+	return nil
+}
+
+func resourceAHCloudServerSnapshotPolicyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := pruneSnapshots(ctx, d, meta); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceAHCloudServerSnapshotPolicyRead(ctx, d, meta)
+}
+
+func resourceAHCloudServerSnapshotPolicyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Removing the policy keeps the remaining snapshots
+	d.SetId("")
+	return nil
+}
+
+// pruneSnapshots deletes the snapshots listed in the plan. Snapshots created after the plan
+// are left for the next one, snapshots that are already gone are skipped.
+func pruneSnapshots(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*CombinedConfig).ahClient()
+
+	for _, v := range d.Get("snapshots_to_prune").([]interface{}) {
+		snapshotID := v.(string)
+		if _, err := client.Backups.Delete(ctx, snapshotID); err == ah.ErrResourceNotFound {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("Error deleting snapshot (%s): %s", snapshotID, err)
+		}
+		if err := waitForBackupDestroy(snapshotID, d, meta); err != nil {
+			return fmt.Errorf("Error waiting for snapshot (%s) to become destroyed: %s", snapshotID, err)
+		}
+	}
+
+	return nil
+}
+
+// snapshotsToPrune returns IDs of the cloud server snapshots older than the newest keep ones.
+// Only snapshots named with namePrefix count. Automatic backups are managed by
+// the backup schedule and never pruned.
+func snapshotsToPrune(ctx context.Context, meta interface{}, instanceID string, keep int, namePrefix string) ([]string, error) {
+	client := meta.(*CombinedConfig).ahClient()
+
+	options := &ah.ListOptions{
+		Filters: []ah.FilterInterface{
+			&ah.InFilter{
+				Keys:   []string{"instance_id"},
+				Values: []string{instanceID},
+			},
+		},
+	}
+
+	instancesBackups, err := client.Backups.List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing snapshots of cloud server (%s): %s", instanceID, err)
+	}
+
+	var snapshots []ah.Backup
+	for _, instanceBackups := range instancesBackups {
+		for _, backup := range instanceBackups.Backups {
+			if backup.InstanceID == instanceID && backup.Type == "snapshot" && strings.HasPrefix(backup.Note, namePrefix) {
+				snapshots = append(snapshots, backup)
+			}
+		}
+	}
+
+	if len(snapshots) <= keep {
+		return []string{}, nil
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt > snapshots[j].CreatedAt
+	})
+
+	snapshotIDs := make([]string, 0, len(snapshots)-keep)
+	for _, snapshot := range snapshots[keep:] {
+		snapshotIDs = append(snapshotIDs, snapshot.ID)
+	}
+	return snapshotIDs, nil
+}
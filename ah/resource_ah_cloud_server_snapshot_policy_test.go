@@ -0,0 +1,169 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccAHCloudServerSnapshotPolicy_Basic(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHCloudServerSnapshotDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckAHCloudServerSnapshotPolicyConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("ah_cloud_server_snapshot_policy.test", "cloud_server_id", "ah_cloud_server.web", "id"),
+					resource.TestCheckResourceAttr("ah_cloud_server_snapshot_policy.test", "keep", "1"),
+					resource.TestCheckResourceAttr("ah_cloud_server_snapshot_policy.test", "name_prefix", "daily-"),
+					resource.TestCheckResourceAttr("ah_cloud_server_snapshot_policy.test", "snapshots_to_prune.#", "0"),
+					testAccCheckAHCloudServerSnapshotsCount("ah_cloud_server.web", 2),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckAHCloudServerSnapshotsCount(n string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		client := testAccProvider.Meta().(*CombinedConfig).ahClient()
+		instancesBackups, err := client.Backups.List(context.Background(), nil)
+		if err != nil {
+			return err
+		}
+
+		var snapshots int
+		for _, instanceBackups := range instancesBackups {
+			for _, backup := range instanceBackups.Backups {
+				if backup.InstanceID == rs.Primary.ID && backup.Type == "snapshot" {
+					snapshots++
+				}
+			}
+		}
+
+		if snapshots != count {
+			return fmt.Errorf("Expected %d snapshots of %s, got %d", count, rs.Primary.ID, snapshots)
+		}
+		return nil
+	}
+}
+
+func testAccCheckAHCloudServerSnapshotPolicyConfigBasic() string {
+	return fmt.Sprintf(`
+	resource "ah_cloud_server" "web" {
+	  name = "test"
+	  datacenter = "%s"
+	  image = "%s"
+	  plan = "%s"
+	}
+
+	resource "ah_cloud_server_snapshot" "first" {
+	  cloud_server_id = ah_cloud_server.web.id
+	  name = "first"
+	}
+
+	resource "ah_cloud_server_snapshot" "second" {
+	  cloud_server_id = ah_cloud_server.web.id
+	  name = "second"
+	  depends_on = [ah_cloud_server_snapshot.first]
+	}
+
+	resource "ah_cloud_server_snapshot_policy" "test" {
+	  cloud_server_id = ah_cloud_server.web.id
+	  keep = 1
+	  name_prefix = "daily-"
+	  depends_on = [ah_cloud_server_snapshot.second]
+	}`, DatacenterName, ImageName, VpsPlanName)
+}
+
+func TestResourceAHCloudServerSnapshotPolicy_PruneOnEveryApply(t *testing.T) {
+	backupDestroyDelay = 0
+
+	backups := &fakeBackupsAPI{
+		backups: []ah.Backup{
+			{ID: "snapshot-1", InstanceID: "server", Type: "snapshot", Note: "daily-1", CreatedAt: "2026-01-01T00:00:00Z"},
+			{ID: "snapshot-2", InstanceID: "server", Type: "snapshot", Note: "daily-2", CreatedAt: "2026-01-02T00:00:00Z"},
+			{ID: "manual", InstanceID: "server", Type: "snapshot", Note: "manual", CreatedAt: "2025-12-01T00:00:00Z"},
+			{ID: "backup", InstanceID: "server", Type: "backup", Note: "daily-0", CreatedAt: "2025-12-01T00:00:00Z"},
+		},
+	}
+	meta := testFakeMeta()
+	meta.client.Backups = backups
+	meta.client.Instances = &fakeInstancesAPI{instances: []ah.Instance{{ID: "server"}}}
+
+	r := resourceAHCloudServerSnapshotPolicy()
+	raw := map[string]interface{}{
+		"cloud_server_id": "server",
+		"keep":            1,
+		"name_prefix":     "daily-",
+	}
+
+	apply := func(state *terraform.InstanceState, beforeApply func()) *terraform.InstanceState {
+		t.Helper()
+		if state == nil {
+			state = &terraform.InstanceState{}
+		}
+		state.RawConfig = testResourceConfig(t, r, raw)
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff.Empty() {
+			t.Fatal("Expected a plan to prune snapshots, got an empty one")
+		}
+		beforeApply()
+		state, diags := r.Apply(context.Background(), state, diff, meta)
+		if diags.HasError() {
+			t.Fatalf("Unexpected errors: %v", diags)
+		}
+		return state
+	}
+
+	state := apply(nil, func() {})
+	if !reflect.DeepEqual(backups.deleted, []string{"snapshot-1"}) {
+		t.Fatalf("Expected snapshot-1 to be deleted on create, got %v", backups.deleted)
+	}
+
+	backups.backups = append(backups.backups,
+		ah.Backup{ID: "snapshot-3", InstanceID: "server", Type: "snapshot", Note: "daily-3", CreatedAt: "2026-01-03T00:00:00Z"},
+		ah.Backup{ID: "snapshot-4", InstanceID: "server", Type: "snapshot", Note: "daily-4", CreatedAt: "2026-01-04T00:00:00Z"},
+	)
+
+	state, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	if diags.HasError() {
+		t.Fatalf("Unexpected errors: %v", diags)
+	}
+	if got := state.Attributes["snapshots_to_prune.#"]; got != "0" {
+		t.Fatalf("Expected no snapshots_to_prune in state, got %s", got)
+	}
+
+	// Only the planned snapshots are deleted: snapshot-5 created after the plan doesn't make
+	// snapshot-4 prunable and snapshot-2 deleted meanwhile is skipped
+	apply(state, func() {
+		backups.backups = append(backups.backups,
+			ah.Backup{ID: "snapshot-5", InstanceID: "server", Type: "snapshot", Note: "daily-5", CreatedAt: "2026-01-05T00:00:00Z"},
+		)
+		if _, err := backups.Delete(context.Background(), "snapshot-2"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	expected := []string{"snapshot-1", "snapshot-2", "snapshot-3"}
+	if !reflect.DeepEqual(backups.deleted, expected) {
+		t.Fatalf("Expected %v to be deleted after the second apply, got %v", expected, backups.deleted)
+	}
+}
@@ -54,6 +54,26 @@ func TestAccAHCloudServerSnapshot_EmptyName(t *testing.T) {
 	})
 }
 
+func TestAccAHCloudServerSnapshot_ExpireAfter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHCloudServerSnapshotDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckAHCloudServerSnapshotConfigExpireAfter(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_cloud_server_snapshot.test", "expire_after", "720h"),
+					resource.TestCheckResourceAttrSet("ah_cloud_server_snapshot.test", "expires_at"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccAHCloudServerSnapshot_UpdateName(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode.")
@@ -146,7 +166,7 @@ func testAccCheckAHCloudServerSnapshotRecreated(t *testing.T, beforeID, afterID
 }
 
 func testAccCheckAHCloudServerSnapshotDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_cloud_server_snapshot" {
@@ -193,6 +213,21 @@ func testAccCheckAHCloudServerSnapshotConfigEmptyName() string {
 	}`, DatacenterName, ImageName, VpsPlanName)
 }
 
+func testAccCheckAHCloudServerSnapshotConfigExpireAfter() string {
+	return fmt.Sprintf(`
+	resource "ah_cloud_server" "web" {
+	  name = "test"
+	  datacenter = "%s"
+	  image = "%s"
+	  product = "%s"
+	}
+
+	resource "ah_cloud_server_snapshot" "test" {
+	  cloud_server_id = ah_cloud_server.web.id
+	  expire_after = "720h"
+	}`, DatacenterName, ImageName, VpsPlanName)
+}
+
 func testAccCheckAHCloudServerSnapshotConfigUpdateName() string {
 	return fmt.Sprintf(`
 	resource "ah_cloud_server" "web" {
@@ -223,3 +258,30 @@ func testAccCheckAHCloudServerSnapshotConfigUpdateCloudServer() string {
 	  name = "example-snapshot-1"
 	}`, DatacenterName, ImageName, VpsPlanName)
 }
+
+func TestResourceAHCloudServerSnapshot_ExpireAfterChange(t *testing.T) {
+	attributes := map[string]string{
+		"id":              "snapshot",
+		"cloud_server_id": "server",
+		"name":            "test",
+		"expire_after":    "24h",
+		"expires_at":      "2099-01-02T00:00:00Z",
+	}
+	raw := map[string]interface{}{
+		"cloud_server_id": "server",
+		"name":            "test",
+		"expire_after":    "48h",
+	}
+
+	diff, err := testResourceUpdateDiff(t, resourceAHCloudServerSnapshot(), attributes, raw, testFakeMeta())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiresAt, ok := diff.Attributes["expires_at"]
+	if !ok || !expiresAt.NewComputed {
+		t.Fatalf("Expected expires_at to be recomputed, got %#v", expiresAt)
+	}
+	if diff.RequiresNew() {
+		t.Fatal("Expected expire_after change to update the snapshot in place")
+	}
+}
@@ -3,11 +3,14 @@ package ah
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/advancedhosting/advancedhosting-api-go/ah"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -98,6 +101,46 @@ func TestAccAHCloudServer_CreateWithAutoBackups(t *testing.T) {
 	})
 }
 
+func TestAccAHCloudServer_CreateWithBackupPolicy(t *testing.T) {
+	name := fmt.Sprintf("test-%s", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHCloudServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckAHCloudServerConfigCreateWithBackupPolicy(name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_cloud_server.web", "name", name),
+					resource.TestCheckResourceAttr("ah_cloud_server.web", "backup_policy.0.enabled", "true"),
+					resource.TestCheckResourceAttr("ah_cloud_server.web", "backup_policy.0.period", "daily"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAHCloudServer_CreateFromSnapshot(t *testing.T) {
+	name := fmt.Sprintf("test-%s", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHCloudServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckAHCloudServerConfigCreateFromSnapshot(name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_cloud_server.restored", "name", name),
+					resource.TestCheckResourceAttr("ah_cloud_server.restored", "state", "running"),
+					resource.TestCheckResourceAttrPair("ah_cloud_server.restored", "source_snapshot_id", "ah_cloud_server_snapshot.snapshot", "id"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccAHCloudServer_CreateWithSSHKey(t *testing.T) {
 	name := fmt.Sprintf("test-%s", acctest.RandString(10))
 
@@ -290,8 +333,251 @@ func TestAccAHCloudServer_UpdateImage(t *testing.T) {
 	})
 }
 
+func TestResourceAHCloudServer_PrivateCloudArguments(t *testing.T) {
+	publicServer := func(extra map[string]interface{}) map[string]interface{} {
+		config := map[string]interface{}{
+			"name":       "test",
+			"datacenter": DatacenterName,
+			"image":      ImageName,
+			"plan":       VpsPlanName,
+		}
+		for k, v := range extra {
+			if v == nil {
+				delete(config, k)
+			} else {
+				config[k] = v
+			}
+		}
+		return config
+	}
+	privateServer := func(without string, extra map[string]interface{}) map[string]interface{} {
+		config := map[string]interface{}{
+			"name":          "test",
+			"datacenter":    DatacenterName,
+			"image":         ImageName,
+			"private_cloud": true,
+			"cluster_id":    "cluster",
+			"node_id":       NodeID,
+			"vcpu":          1,
+			"ram":           1024,
+			"disk":          10,
+		}
+		delete(config, without)
+		for k, v := range extra {
+			config[k] = v
+		}
+		return config
+	}
+
+	cases := []struct {
+		name        string
+		config      map[string]interface{}
+		expectedErr string
+	}{
+		{"public", publicServer(nil), ""},
+		{"public with product", publicServer(map[string]interface{}{"plan": nil, "product": VpsPlanName}), ""},
+		{"public without plan", publicServer(map[string]interface{}{"plan": nil}), "one of plan or product must be configured"},
+		{"public with cluster_id", publicServer(map[string]interface{}{"cluster_id": "cluster"}), "cluster_id can only be set when private_cloud is true"},
+		{"public with node_id", publicServer(map[string]interface{}{"node_id": NodeID}), "node_id can only be set when private_cloud is true"},
+		{"public with network_id", publicServer(map[string]interface{}{"network_id": "network"}), "network_id can only be set when private_cloud is true"},
+		{"public with vcpu", publicServer(map[string]interface{}{"vcpu": 1}), "vcpu can only be set when private_cloud is true"},
+		{"public with ram", publicServer(map[string]interface{}{"ram": 1024}), "ram can only be set when private_cloud is true"},
+		{"public with disk", publicServer(map[string]interface{}{"disk": 10}), "disk can only be set when private_cloud is true"},
+		{"private", privateServer("", nil), ""},
+		{"private with network_id", privateServer("", map[string]interface{}{"network_id": "network"}), ""},
+		{"private without cluster_id", privateServer("cluster_id", nil), "cluster_id is required when private_cloud is true"},
+		{"private without node_id", privateServer("node_id", nil), "node_id is required when private_cloud is true"},
+		{"private without vcpu", privateServer("vcpu", nil), "vcpu is required when private_cloud is true"},
+		{"private without ram", privateServer("ram", nil), "ram is required when private_cloud is true"},
+		{"private without disk", privateServer("disk", nil), "disk is required when private_cloud is true"},
+		{"private with plan", privateServer("", map[string]interface{}{"plan": VpsPlanName}), "plan can't be set when private_cloud is true"},
+		{"private with product", privateServer("", map[string]interface{}{"product": VpsPlanName}), "product can't be set when private_cloud is true"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := testResourceDiff(t, resourceAHCloudServer(), tc.config, testFakeMeta())
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestResourceAHCloudServer_PrivateCloudChanges(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "instance-id",
+		Attributes: map[string]string{
+			"id":            "instance-id",
+			"name":          "test",
+			"datacenter":    DatacenterName,
+			"image":         ImageName,
+			"private_cloud": "true",
+			"cluster_id":    "cluster",
+			"node_id":       NodeID,
+			"vcpu":          "2",
+			"ram":           "2048",
+			"disk":          "20",
+		},
+	}
+	config := func(key string, value interface{}) map[string]interface{} {
+		raw := map[string]interface{}{
+			"name":          "test",
+			"datacenter":    DatacenterName,
+			"image":         ImageName,
+			"private_cloud": true,
+			"cluster_id":    "cluster",
+			"node_id":       NodeID,
+			"vcpu":          2,
+			"ram":           2048,
+			"disk":          20,
+		}
+		raw[key] = value
+		return raw
+	}
+
+	cases := []struct {
+		name        string
+		config      map[string]interface{}
+		expectedErr string
+	}{
+		{"unchanged", config("vcpu", 2), ""},
+		{"disk shrink", config("disk", 10), "can't be shrunk from 20 GB to 10 GB"},
+		{"disk growth", config("disk", 40), "disk of private cloud server instance-id can't be changed in place"},
+		{"ram change", config("ram", 4096), "ram of private cloud server instance-id can't be changed in place"},
+		{"vcpu change", config("vcpu", 4), "vcpu of private cloud server instance-id can't be changed in place"},
+		{"node change", config("node_id", "another-node"), "node_id of private cloud server instance-id can't be changed"},
+		{"cluster change", config("cluster_id", "another-cluster"), "cluster_id of private cloud server instance-id can't be changed"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := state.DeepCopy()
+			s.RawConfig = testResourceConfig(t, resourceAHCloudServer(), tc.config)
+			_, err := resourceAHCloudServer().Diff(context.Background(), s, terraform.NewResourceConfigRaw(tc.config), testFakeMeta())
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestResourceAHCloudServer_PlanDowngrade(t *testing.T) {
+	cases := []struct {
+		oldPlan     string
+		newPlan     string
+		expectedErr string
+		warning     bool
+	}{
+		{"start-xs", "start-m", "", false},
+		{"1", "2", "", false},
+		{"start-m", "start-xs", "can't be changed from start-m (vcpu: 2, ram: 2048, disk: 40) to start-xs (vcpu: 1, ram: 1024, disk: 20)", true},
+		{"cpu-m", "ram-m", "", true},
+		{"ram-m", "start-m", "", true},
+		{"retired-plan", "start-m", "", false},
+	}
+
+	meta := testFakeMeta()
+	for _, tc := range cases {
+		t.Run(tc.oldPlan+" to "+tc.newPlan, func(t *testing.T) {
+			state := &terraform.InstanceState{
+				ID: "instance-id",
+				Attributes: map[string]string{
+					"id":         "instance-id",
+					"name":       "test",
+					"datacenter": DatacenterName,
+					"image":      ImageName,
+					"plan":       tc.oldPlan,
+				},
+			}
+			config := map[string]interface{}{
+				"name":       "test",
+				"datacenter": DatacenterName,
+				"image":      ImageName,
+				"plan":       tc.newPlan,
+			}
+			state.RawConfig = testResourceConfig(t, resourceAHCloudServer(), config)
+
+			_, err := resourceAHCloudServer().Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+			if tc.expectedErr == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedErr)) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+
+			oldPlan, newPlan, ok := cloudServerPlanChange("instance-id", meta, tc.oldPlan, tc.newPlan)
+			if !ok {
+				if tc.warning {
+					t.Fatal("expected both plans to resolve")
+				}
+				return
+			}
+			if warning := planDowngradeWarning(oldPlan, newPlan); (warning != "") != tc.warning {
+				t.Fatalf("expected warning: %t, got %q", tc.warning, warning)
+			}
+		})
+	}
+}
+
+func TestResourceAHCloudServer_ExpandCreateRequest(t *testing.T) {
+	client := testFakeAPIClient()
+	client.SSHKeys = &fakeSSHKeysAPI{
+		sshKeys: []ah.SSHKey{{ID: "ssh-key-id", Fingerprint: "7e:ac:a8:45:83:e3:58:f5:3a:9f:dd:16:63:dc:fb:1e"}},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceAHCloudServer().Schema, map[string]interface{}{
+		"name":          "test",
+		"datacenter":    DatacenterID,
+		"image":         ImageName,
+		"private_cloud": true,
+		"cluster_id":    "cluster",
+		"node_id":       NodeID,
+		"network_id":    "network",
+		"vcpu":          2,
+		"ram":           2048,
+		"disk":          20,
+		"ssh_keys":      []interface{}{"7e:ac:a8:45:83:e3:58:f5:3a:9f:dd:16:63:dc:fb:1e"},
+	})
+
+	request, err := expandInstanceCreateRequest(d, &CombinedConfig{client: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &ah.InstanceCreateRequest{
+		Name:                  "test",
+		DatacenterID:          DatacenterID,
+		ImageSlug:             ImageName,
+		PrivateCloud:          true,
+		ClusterID:             "cluster",
+		NodeID:                NodeID,
+		IPNetworkID:           "network",
+		Vcpu:                  2,
+		Ram:                   2048,
+		Disk:                  20,
+		SSHKeyIDs:             []string{"ssh-key-id"},
+		CreatePublicIPAddress: true,
+	}
+	if !reflect.DeepEqual(request, expected) {
+		t.Fatalf("expected request %+v, got %+v", expected, request)
+	}
+}
+
 func testAccCheckAHCloudServerDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_cloud_server" {
@@ -351,6 +637,39 @@ func testAccCheckAHCloudServerConfigCreateWithAutoBackups(name string) string {
 	}`, name, DatacenterName, ImageName, VpsPlanName)
 }
 
+func testAccCheckAHCloudServerConfigCreateWithBackupPolicy(name string) string {
+	return fmt.Sprintf(`
+	 resource "ah_cloud_server" "web" {
+	   name = "%s"
+	   datacenter = "%s"
+	   image = "%s"
+	   plan = "%s"
+	   backup_policy {
+	     enabled = true
+	     period = "daily"
+	   }
+	 }`, name, DatacenterName, ImageName, VpsPlanName)
+}
+
+func testAccCheckAHCloudServerConfigCreateFromSnapshot(name string) string {
+	return fmt.Sprintf(`
+	 resource "ah_cloud_server" "web" {
+	   name = "%s"
+	   datacenter = "%s"
+	   image = "%s"
+	   plan = "%s"
+	 }
+	 resource "ah_cloud_server_snapshot" "snapshot" {
+	   cloud_server_id = ah_cloud_server.web.id
+	 }
+	 resource "ah_cloud_server" "restored" {
+	   name = "%[1]s"
+	   datacenter = "%[2]s"
+	   source_snapshot_id = ah_cloud_server_snapshot.snapshot.id
+	   plan = "%[4]s"
+	 }`, name, DatacenterName, ImageName, VpsPlanName)
+}
+
 func testAccCheckAHCloudServerConfigCreateInPrivateCloud(name string) string {
 	return fmt.Sprintf(`
 	 resource "ah_cloud_server" "web" {
@@ -441,3 +760,234 @@ func testAccCheckAHCloudServerExists(n string, instanceID *string) resource.Test
 		return nil
 	}
 }
+
+func TestBackupPolicyEqual(t *testing.T) {
+	policy := func(enabled bool, period string) []interface{} {
+		return []interface{}{map[string]interface{}{"enabled": enabled, "period": period}}
+	}
+
+	cases := []struct {
+		name     string
+		old, new []interface{}
+		expected bool
+	}{
+		{"same", policy(true, "daily"), policy(true, "daily"), true},
+		{"enabled", policy(false, "weekly"), policy(true, "weekly"), false},
+		{"period", policy(true, "daily"), policy(true, "weekly"), false},
+		{"period while disabled", policy(false, "daily"), policy(false, "weekly"), true},
+		{"removed", policy(true, "daily"), nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if equal := backupPolicyEqual(tc.old, tc.new); equal != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, equal)
+			}
+		})
+	}
+}
+
+func TestResourceAHCloudServer_BackupsChange(t *testing.T) {
+	attributes := map[string]string{
+		"id":                      "instance-id",
+		"name":                    "test",
+		"datacenter":              DatacenterName,
+		"image":                   ImageName,
+		"plan":                    "start-m",
+		"backups":                 "false",
+		"backup_policy.#":         "1",
+		"backup_policy.0.enabled": "false",
+		"backup_policy.0.period":  "weekly",
+	}
+
+	cases := []struct {
+		name        string
+		config      map[string]interface{}
+		expectedErr string
+	}{
+		{"unchanged backups", map[string]interface{}{"backups": false}, ""},
+		{"toggled backups", map[string]interface{}{"backups": true}, "backups of an existing cloud server can't be changed"},
+		{"unchanged backup_policy", map[string]interface{}{
+			"backup_policy": []interface{}{map[string]interface{}{"enabled": false, "period": "daily"}},
+		}, ""},
+		{"toggled backup_policy", map[string]interface{}{
+			"backup_policy": []interface{}{map[string]interface{}{"enabled": true, "period": "weekly"}},
+		}, "backup_policy of an existing cloud server can't be changed"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"name":       "test",
+				"datacenter": DatacenterName,
+				"image":      ImageName,
+				"plan":       "start-m",
+			}
+			for k, v := range tc.config {
+				config[k] = v
+			}
+
+			_, err := testResourceUpdateDiff(t, resourceAHCloudServer(), attributes, config, testFakeMeta())
+			if tc.expectedErr == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedErr)) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestResourceAHCloudServer_SourceSnapshot(t *testing.T) {
+	meta := testFakeMeta()
+	meta.client.Backups = &fakeBackupsAPI{
+		backups: []ah.Backup{
+			{ID: "1d3c5a7e-0b2f-4c6d-8e9a-1b3d5f7a9c01", Status: "ready", MinDiskSize: 20},
+			{ID: "2e4d6b8f-1c3a-4d7e-9fab-2c4e6a8b0d12", Status: "ready", MinDiskSize: 30},
+			{ID: "3f5e7c9a-2d4b-4e8f-a0bc-3d5f7b9c1e23", Status: "creating", MinDiskSize: 20},
+		},
+	}
+
+	cases := []struct {
+		name        string
+		snapshotID  string
+		expectedErr string
+	}{
+		{"ready snapshot", "1d3c5a7e-0b2f-4c6d-8e9a-1b3d5f7a9c01", ""},
+		{"disk too small", "2e4d6b8f-1c3a-4d7e-9fab-2c4e6a8b0d12", "requires a disk of at least 30 GB, the requested disk is 20 GB"},
+		{"snapshot not ready", "3f5e7c9a-2d4b-4e8f-a0bc-3d5f7b9c1e23", "is creating, only ready snapshots can be used"},
+		{"missing snapshot", "4a6f8d0b-3e5c-4f9a-b1cd-4e6a8c0d2f34", "Error getting snapshot"},
+	}
+
+	for _, tc := range cases {
+		config := map[string]interface{}{
+			"name":               "test",
+			"datacenter":         DatacenterName,
+			"plan":               "start-xs",
+			"source_snapshot_id": tc.snapshotID,
+		}
+		checkErr := func(t *testing.T, err error) {
+			t.Helper()
+			if tc.expectedErr == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedErr)) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		}
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := testResourceDiff(t, resourceAHCloudServer(), config, meta)
+			checkErr(t, err)
+		})
+
+		// A new snapshot replaces an existing server and is validated the same way
+		t.Run(tc.name+" replacing a server", func(t *testing.T) {
+			attributes := map[string]string{
+				"id":                 "instance-id",
+				"name":               "test",
+				"datacenter":         DatacenterName,
+				"plan":               "start-xs",
+				"source_snapshot_id": "5b7a9e1c-4f6d-4a0b-c2de-5f7b9d1e3a45",
+			}
+			_, err := testResourceUpdateDiff(t, resourceAHCloudServer(), attributes, config, meta)
+			checkErr(t, err)
+		})
+	}
+}
+
+func TestResourceAHCloudServerImport_Image(t *testing.T) {
+	snapshotID := "1d3c5a7e-0b2f-4c6d-8e9a-1b3d5f7a9c01"
+	imageID := "8f1d3c2b-7a4e-4b6d-a5c9-3e2f1d0b9a87"
+
+	cases := []struct {
+		name             string
+		image            *ah.InstanceImage
+		expectedImage    string
+		expectedSnapshot string
+	}{
+		{"image slug", &ah.InstanceImage{Image: &ah.Image{ID: imageID, Slug: "ubuntu-18_04-x64"}}, "ubuntu-18_04-x64", ""},
+		{"image without slug", &ah.InstanceImage{Image: &ah.Image{ID: imageID}}, imageID, ""},
+		{"snapshot", &ah.InstanceImage{Image: &ah.Image{ID: snapshotID}}, "", snapshotID},
+		{"no image", nil, "", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			meta := testFakeMeta()
+			meta.client.Backups = &fakeBackupsAPI{backups: []ah.Backup{{ID: snapshotID, Type: "snapshot", Status: "ready"}}}
+			meta.client.Instances = &fakeInstancesAPI{instances: []ah.Instance{{
+				ID:         "server",
+				Datacenter: &ah.Datacenter{ID: DatacenterID, Slug: DatacenterName},
+				Image:      tc.image,
+			}}}
+
+			d := resourceAHCloudServer().TestResourceData()
+			d.SetId("server")
+			if _, err := resourceAHCloudServerImport(context.Background(), d, meta); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if image := d.Get("image").(string); image != tc.expectedImage {
+				t.Fatalf("expected image %q, got %q", tc.expectedImage, image)
+			}
+			if snapshotID := d.Get("source_snapshot_id").(string); snapshotID != tc.expectedSnapshot {
+				t.Fatalf("expected source_snapshot_id %q, got %q", tc.expectedSnapshot, snapshotID)
+			}
+		})
+	}
+}
+
+func TestResourceAHCloudServerStateUpgradeV0(t *testing.T) {
+	v0State := map[string]interface{}{
+		"id":         "server",
+		"name":       "test",
+		"datacenter": DatacenterName,
+		"image":      ImageName,
+		"plan":       "start-xs",
+		"node_id":    "false",
+		"cluster_id": "false",
+	}
+	config := map[string]interface{}{
+		"name":       "test",
+		"datacenter": DatacenterName,
+		"image":      ImageName,
+		"plan":       "start-xs",
+	}
+
+	attributes := func(state map[string]interface{}) map[string]string {
+		result := make(map[string]string, len(state))
+		for k, v := range state {
+			result[k] = v.(string)
+		}
+		return result
+	}
+
+	diff, err := testResourceUpdateDiff(t, resourceAHCloudServer(), attributes(v0State), config, testFakeMeta())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := diff.Attributes["node_id"]; !ok {
+		t.Fatal("expected the version 0 state to plan a node_id change")
+	}
+
+	upgraded, err := resourceAHCloudServerStateUpgradeV0(context.Background(), v0State, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := upgraded["node_id"]; ok {
+		t.Fatalf("expected node_id to be dropped, got %v", upgraded["node_id"])
+	}
+	if _, ok := upgraded["cluster_id"]; ok {
+		t.Fatalf("expected cluster_id to be dropped, got %v", upgraded["cluster_id"])
+	}
+
+	diff, err = testResourceUpdateDiff(t, resourceAHCloudServer(), attributes(upgraded), config, testFakeMeta())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"node_id", "cluster_id"} {
+		if attr, ok := diff.Attributes[key]; ok {
+			t.Fatalf("expected no %s change after the upgrade, got %#v", key, attr)
+		}
+	}
+}
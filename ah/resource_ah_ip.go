@@ -46,7 +46,7 @@ func resourceAHIP() *schema.Resource {
 }
 
 func resourceAHIPCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	addressType := d.Get("type").(string)
 	request := &ah.IPAddressCreateRequest{
@@ -82,7 +82,7 @@ func resourceAHIPCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHIPRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	ipAddress, err := client.IPAddresses.Get(context.Background(), d.Id())
 	if err != nil {
 		return err
@@ -96,7 +96,7 @@ func resourceAHIPRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHIPUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	if d.HasChange("reverse_dns") {
 		reverseDNS := d.Get("reverse_dns").(string)
@@ -115,7 +115,7 @@ func resourceAHIPUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHIPDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.IPAddresses.Delete(context.Background(), d.Id()); err != nil {
 		if err == ah.ErrResourceNotFound {
 			return nil
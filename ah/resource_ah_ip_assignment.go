@@ -39,7 +39,7 @@ func resourceAHIPAssignment() *schema.Resource {
 }
 
 func resourceAHIPAssignmentCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	request := &ah.IPAddressAssignmentCreateRequest{
 		InstanceID: d.Get("cloud_server_id").(string),
@@ -79,7 +79,7 @@ func resourceAHIPAssignmentCreate(d *schema.ResourceData, meta interface{}) erro
 
 func resourceAHIPAssignmentRead(d *schema.ResourceData, meta interface{}) error {
 
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	instanceID := d.Get("cloud_server_id").(string)
 
 	instance, err := client.Instances.Get(context.Background(), instanceID)
@@ -107,7 +107,7 @@ func resourceAHIPAssignmentUpdate(d *schema.ResourceData, meta interface{}) erro
 }
 
 func resourceAHIPAssignmentDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.IPAddressAssignments.Delete(context.Background(), d.Id()); err != nil {
 		return fmt.Errorf(
 			"Error deleting ip address assignment (%s): %s", d.Id(), err)
@@ -123,7 +123,7 @@ func resourceAHIPAssignmentDelete(d *schema.ResourceData, meta interface{}) erro
 
 func setIPAsPrimary(d *schema.ResourceData, meta interface{}) error {
 
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	instanceID := d.Get("cloud_server_id").(string)
 
 	action, err := client.Instances.SetPrimaryIP(context.Background(), instanceID, d.Id())
@@ -140,7 +140,7 @@ func setIPAsPrimary(d *schema.ResourceData, meta interface{}) error {
 }
 
 func waitForInstanceAction(actionID string, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	instanceID := d.Get("cloud_server_id").(string)
 
 	stateRefreshFunc := func() (interface{}, string, error) {
@@ -172,7 +172,7 @@ func waitForInstanceAction(actionID string, d *schema.ResourceData, meta interfa
 }
 
 func waitIPAssignmentReady(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		ipAddressAssignment, err := client.IPAddressAssignments.Get(context.Background(), d.Id())
@@ -203,7 +203,7 @@ func waitIPAssignmentReady(d *schema.ResourceData, meta interface{}) error {
 }
 
 func waitIPAssignmentDestroy(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		ipAddressAssignment, err := client.IPAddressAssignments.Get(context.Background(), d.Id())
@@ -238,7 +238,7 @@ func waitIPAssignmentDestroy(d *schema.ResourceData, meta interface{}) error {
 }
 
 func ipAddressByIP(ip string, meta interface{}) (*ah.IPAddress, error) {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	options := &ah.ListOptions{
 		Filters: []ah.FilterInterface{
 			&ah.InFilter{
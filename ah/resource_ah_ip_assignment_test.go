@@ -94,7 +94,7 @@ func TestAccAHIPAssignment_UpdateToPrimary(t *testing.T) {
 }
 
 func testAccCheckAHIPAssignmentDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_ip_assignment" {
@@ -174,7 +174,7 @@ func TestAccAHIP_UpdateType(t *testing.T) {
 }
 
 func testAccCheckAHIPDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_ip" {
@@ -73,12 +73,18 @@ func resourceAHK8sCluster() *schema.Resource {
 					Schema: WorkerPoolSchema,
 				},
 			},
+			// monthly_cost is set by the plan so it shows the cost of new resources and plan changes
+			"monthly_cost": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
 		},
+		CustomizeDiff: k8sClusterCostDiff,
 	}
 }
 
 func resourceAHK8sClusterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	var datacenterID string
 	var nodePools []ah.CreateKubernetesWorkerPoolRequest
@@ -88,14 +94,14 @@ func resourceAHK8sClusterCreate(ctx context.Context, d *schema.ResourceData, met
 	if _, err := uuid.Parse(datacenterAttr); err == nil {
 		datacenterID = datacenterAttr
 	} else {
-		id, err := datacenterIDBySlug(ctx, client, datacenterAttr)
+		id, err := datacenterIDBySlug(ctx, meta, datacenterAttr)
 		if err != nil {
 			return diag.FromErr(err)
 		}
 		datacenterID = id
 	}
 
-	k8sVersion, err := kubernetesVersion(ctx, client, d.Get("k8s_version").(string))
+	k8sVersion, err := kubernetesVersion(ctx, meta, d.Get("k8s_version").(string))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -133,7 +139,7 @@ func resourceAHK8sClusterCreate(ctx context.Context, d *schema.ResourceData, met
 }
 
 func resourceAHK8sClusterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	clusterID := d.Id()
 	if clusterID == "" {
@@ -164,7 +170,7 @@ func resourceAHK8sClusterRead(ctx context.Context, d *schema.ResourceData, meta
 }
 
 func resourceAHK8sClusterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	if !d.HasChanges("name") {
 		return resourceAHK8sClusterRead(ctx, d, meta)
@@ -184,7 +190,7 @@ func resourceAHK8sClusterUpdate(ctx context.Context, d *schema.ResourceData, met
 }
 
 func resourceAHK8sClusterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.KubernetesClusters.Delete(ctx, d.Id()); err != nil {
 		return diag.Errorf(
 			"Error deleting k8s cluster (%s): %s", d.Id(), err)
@@ -199,7 +205,7 @@ func resourceAHK8sClusterDelete(ctx context.Context, d *schema.ResourceData, met
 }
 
 func waitForK8sClusterStatus(ctx context.Context, pendingStatuses, targetStatuses []string, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		cluster, err := client.KubernetesClusters.Get(context.Background(), d.Id())
@@ -228,7 +234,7 @@ func waitForK8sClusterStatus(ctx context.Context, pendingStatuses, targetStatuse
 }
 
 func waitForK8sClusterDestroy(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		cluster, err := client.KubernetesClusters.Get(context.Background(), d.Id())
@@ -99,7 +99,7 @@ func testAccCheckAHK8sClusterExists(n string, clusterID *string) resource.TestCh
 }
 
 func testAccCheckAHK8sClusterDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_k8s_cluster" {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -23,6 +24,10 @@ func resourceAHLoadBalancer() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: customdiff.All(
+			loadBalancerHealthCheckDiff,
+			loadBalancerForwardingRulesDiff,
+		),
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -41,6 +46,11 @@ func resourceAHLoadBalancer() *schema.Resource {
 				Optional: true,
 				Default:  true,
 			},
+			"rollback_on_failure": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"state": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -48,7 +58,16 @@ func resourceAHLoadBalancer() *schema.Resource {
 			"balancing_algorithm": {
 				Type:         schema.TypeString,
 				Optional:     true,
-				ValidateFunc: validation.NoZeroValues,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{"round_robin", "least_requests"}, false),
+			},
+			"proxy_protocol": {
+				Type:     schema.TypeString,
+				Optional: true,
+				// Update requests omit an empty proxy_protocol and the API has no value to turn it
+				// off, so removing the argument keeps the current version.
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{"v1", "v2"}, false),
 			},
 			"instance_count": {
 				Type:         schema.TypeInt,
@@ -58,7 +77,10 @@ func resourceAHLoadBalancer() *schema.Resource {
 			"ip_address": {
 				Type:     schema.TypeSet,
 				Optional: true,
-				Computed: true,
+				// Computed keeps the address created by create_public_ip_address,
+				// ip_address = [] releases all addresses.
+				Computed:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"id": {
@@ -100,6 +122,10 @@ func resourceAHLoadBalancer() *schema.Resource {
 			"backend_node": {
 				Type:     schema.TypeSet,
 				Optional: true,
+				// Computed leaves nodes registered by ah_load_balancer_backend_node alone,
+				// backend_node = [] removes all of them.
+				Computed:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"id": {
@@ -116,6 +142,10 @@ func resourceAHLoadBalancer() *schema.Resource {
 			"forwarding_rule": {
 				Type:     schema.TypeSet,
 				Optional: true,
+				// Computed leaves rules created by ah_load_balancer_forwarding_rule alone,
+				// forwarding_rule = [] removes all of them.
+				Computed:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"id": {
@@ -123,20 +153,24 @@ func resourceAHLoadBalancer() *schema.Resource {
 							Computed: true,
 						},
 						"request_protocol": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(loadBalancerProtocols, false),
 						},
 						"request_port": {
-							Type:     schema.TypeInt,
-							Required: true,
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IsPortNumber,
 						},
 						"communication_protocol": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(loadBalancerProtocols, false),
 						},
 						"communication_port": {
-							Type:     schema.TypeInt,
-							Required: true,
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IsPortNumber,
 						},
 					},
 				},
@@ -144,7 +178,11 @@ func resourceAHLoadBalancer() *schema.Resource {
 			"health_check": {
 				Type:     schema.TypeList,
 				Optional: true,
-				MaxItems: 1,
+				// Computed leaves a health check created by ah_load_balancer_health_check alone,
+				// health_check = [] removes it.
+				Computed:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
+				MaxItems:   1,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"id": {
@@ -192,17 +230,19 @@ func resourceAHLoadBalancer() *schema.Resource {
 }
 
 func resourceAHLoadBalancerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	request := &ah.LoadBalancerCreateRequest{
 		Name:                  d.Get("name").(string),
 		CreatePublicIPAddress: d.Get("create_public_ip_address").(bool),
 		InstanceCount:         d.Get("instance_count").(int),
+		BalancingAlgorithm:    d.Get("balancing_algorithm").(string),
+		ProxyProtocol:         d.Get("proxy_protocol").(string),
 	}
 
 	datacenterAttr := d.Get("datacenter").(string)
 	if _, err := uuid.Parse(datacenterAttr); err != nil {
-		datacenterID, err := datacenterIDBySlug(ctx, client, d.Get("datacenter").(string))
+		datacenterID, err := datacenterIDBySlug(ctx, meta, d.Get("datacenter").(string))
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -275,7 +315,7 @@ func resourceAHLoadBalancerCreate(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceAHLoadBalancerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	loadBalancer, err := client.LoadBalancers.Get(ctx, d.Id())
 	if err != nil {
 		return diag.FromErr(err)
@@ -283,71 +323,64 @@ func resourceAHLoadBalancerRead(ctx context.Context, d *schema.ResourceData, met
 
 	d.Set("name", loadBalancer.Name)
 	d.Set("state", loadBalancer.State)
+	d.Set("balancing_algorithm", loadBalancer.BalancingAlgorithm)
+	d.Set("proxy_protocol", loadBalancer.ProxyProtocol)
 
-	ipsAddresses := make([]map[string]interface{}, len(loadBalancer.IPAddresses))
-	for i, ipAddress := range loadBalancer.IPAddresses {
-		item := make(map[string]interface{})
-		item["id"] = ipAddress.ID
-		item["type"] = ipAddress.Type
-		item["address"] = ipAddress.Address
-		item["state"] = ipAddress.State
-		ipsAddresses[i] = item
-	}
-	d.Set("ip_address", ipsAddresses)
-
-	privateNetworks := make([]map[string]interface{}, len(loadBalancer.PrivateNetworks))
-	for i, pn := range loadBalancer.PrivateNetworks {
-		item := make(map[string]interface{})
-		item["id"] = pn.ID
-		item["state"] = pn.State
-		privateNetworks[i] = item
-
-	}
-	d.Set("private_network", privateNetworks)
+	d.Set("ip_address", flattenLoadBalancerIPAddresses(loadBalancer))
 
-	backendNodes := make([]map[string]interface{}, len(loadBalancer.BackendNodes))
-	for i, bn := range loadBalancer.BackendNodes {
-		item := make(map[string]interface{})
-		item["id"] = bn.ID
-		item["cloud_server_id"] = bn.CloudServerID
-		backendNodes[i] = item
+	d.Set("private_network", flattenLoadBalancerPrivateNetworks(loadBalancer))
+	d.Set("backend_node", flattenLoadBalancerBackendNodes(loadBalancer))
+	d.Set("forwarding_rule", flattenLoadBalancerForwardingRules(loadBalancer))
+	d.Set("health_check", flattenLoadBalancerHealthCheck(loadBalancer))
 
-	}
-	d.Set("backend_node", backendNodes)
+	return nil
+}
 
-	forwardingRules := make([]map[string]interface{}, len(loadBalancer.ForwardingRules))
-	for i, fr := range loadBalancer.ForwardingRules {
-		item := make(map[string]interface{})
-		item["id"] = fr.ID
-		item["request_protocol"] = fr.RequestProtocol
-		item["request_port"] = fr.RequestPort
-		item["communication_protocol"] = fr.CommunicationProtocol
-		item["communication_port"] = fr.CommunicationPort
-		forwardingRules[i] = item
+func resourceAHLoadBalancerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*CombinedConfig).ahClient()
+
+	locks := &meta.(*CombinedConfig).loadBalancerLocks
+	locks.Lock(d.Id())
+	defer locks.Unlock(d.Id())
+
+	// When a step fails the load balancer is read back, so state records the completed steps
+	// instead of the configuration and the next run retries the rest. d.Partial can't be used
+	// for this, it keeps the previous state and drops the values read back.
+	//
+	// rollback holds the steps that undo started changes, rollback_on_failure runs them in reverse.
+	var rollback []func() error
+	instanceCountUpdated := false
+	failed := func(diags diag.Diagnostics) diag.Diagnostics {
+		if d.Get("rollback_on_failure").(bool) {
+			for i := len(rollback) - 1; i >= 0; i-- {
+				if err := rollback[i](); err != nil {
+					diags = append(diags, diag.Errorf("Error rolling back load balancer (%s): %s", d.Id(), err)...)
+				}
+			}
+		}
+		// instance_count isn't read back
+		if !instanceCountUpdated {
+			oldInstanceCount, _ := d.GetChange("instance_count")
+			d.Set("instance_count", oldInstanceCount)
+		}
+		return append(diags, resourceAHLoadBalancerRead(ctx, d, meta)...)
 	}
-	d.Set("forwarding_rule", forwardingRules)
 
-	if loadBalancer.HealthCheck.ID != "" {
-		healthCheck := map[string]interface{}{
-			"id":                  loadBalancer.HealthCheck.ID,
-			"type":                loadBalancer.HealthCheck.Type,
-			"url":                 loadBalancer.HealthCheck.URL,
-			"interval":            loadBalancer.HealthCheck.Interval,
-			"timeout":             loadBalancer.HealthCheck.Timeout,
-			"unhealthy_threshold": loadBalancer.HealthCheck.UnhealthyThreshold,
-			"healthy_threshold":   loadBalancer.HealthCheck.HealthyThreshold,
-			"port":                loadBalancer.HealthCheck.Port,
+	// Every step waits for the load balancer, so dependent resources never see it updating
+	waitForReady := func() diag.Diagnostics {
+		if err := waitForLoadBalancerStatus(ctx, []string{"updating"}, []string{"active"}, d, meta); err != nil {
+			return failed(diag.Errorf(
+				"Error waiting for load balancer (%s) to become ready: %s", d.Id(), err))
 		}
-		d.Set("health_check", []map[string]interface{}{healthCheck})
+		return nil
 	}
 
-	return nil
-}
-
-func resourceAHLoadBalancerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
-
 	if d.HasChange("name") {
+		oldName, _ := d.GetChange("name")
+		rollback = append(rollback, func() error {
+			return updateLoadBalancerAttributes(ctx, d, meta, &ah.LoadBalancerUpdateRequest{Name: oldName.(string)})
+		})
+
 		request := &ah.LoadBalancerUpdateRequest{
 			Name: d.Get("name").(string),
 		}
@@ -355,12 +388,22 @@ func resourceAHLoadBalancerUpdate(ctx context.Context, d *schema.ResourceData, m
 		err := client.LoadBalancers.Update(ctx, d.Id(), request)
 
 		if err != nil {
-			return diag.Errorf(
-				"Error renaming load balancer (%s): %s", d.Id(), err)
+			return failed(diag.Errorf(
+				"Error renaming load balancer (%s): %s", d.Id(), err))
+		}
+
+		if diags := waitForReady(); diags != nil {
+			return diags
 		}
 	}
 
 	if d.HasChange("balancing_algorithm") {
+		if oldAlgorithm, _ := d.GetChange("balancing_algorithm"); oldAlgorithm.(string) != "" {
+			rollback = append(rollback, func() error {
+				return updateLoadBalancerAttributes(ctx, d, meta, &ah.LoadBalancerUpdateRequest{BalancingAlgorithm: oldAlgorithm.(string)})
+			})
+		}
+
 		request := &ah.LoadBalancerUpdateRequest{
 			BalancingAlgorithm: d.Get("balancing_algorithm").(string),
 		}
@@ -368,25 +411,64 @@ func resourceAHLoadBalancerUpdate(ctx context.Context, d *schema.ResourceData, m
 		err := client.LoadBalancers.Update(ctx, d.Id(), request)
 
 		if err != nil {
-			return diag.Errorf(
-				"Error changing load balancer balancing_algorithm (%s): %s", d.Id(), err)
+			return failed(diag.Errorf(
+				"Error changing load balancer balancing_algorithm (%s): %s", d.Id(), err))
 		}
 
-		if err := waitForLoadBalancerStatus(ctx, []string{"updating"}, []string{"active"}, d, meta); err != nil {
-			return diag.Errorf(
-				"Error waiting for load balancer (%s) to become ready: %s", d.Id(), err)
+		if diags := waitForReady(); diags != nil {
+			return diags
+		}
+	}
+
+	if d.HasChange("proxy_protocol") {
+		if oldProxyProtocol, _ := d.GetChange("proxy_protocol"); oldProxyProtocol.(string) != "" {
+			rollback = append(rollback, func() error {
+				return updateLoadBalancerAttributes(ctx, d, meta, &ah.LoadBalancerUpdateRequest{ProxyProtocol: oldProxyProtocol.(string)})
+			})
+		}
+
+		request := &ah.LoadBalancerUpdateRequest{
+			ProxyProtocol: d.Get("proxy_protocol").(string),
+		}
+
+		err := client.LoadBalancers.Update(ctx, d.Id(), request)
+
+		if err != nil {
+			return failed(diag.Errorf(
+				"Error changing load balancer proxy_protocol (%s): %s", d.Id(), err))
 		}
 
+		if diags := waitForReady(); diags != nil {
+			return diags
+		}
 	}
 
 	if d.HasChange("forwarding_rule") {
-		err := updateForwardingRules(ctx, d, meta)
+		oldFRs, newFRs := d.GetChange("forwarding_rule")
+		rollback = append(rollback, func() error {
+			return restoreLoadBalancerChildren(ctx, d, meta, oldFRs.(*schema.Set).List(), flattenLoadBalancerForwardingRules, updateForwardingRules)
+		})
+
+		err := updateForwardingRules(ctx, d, meta, oldFRs.(*schema.Set).List(), newFRs.(*schema.Set).List())
 		if err != nil {
-			return diag.FromErr(err)
+			return failed(diag.FromErr(err))
+		}
+
+		if diags := waitForReady(); diags != nil {
+			return diags
 		}
 	}
 
 	if d.HasChange("instance_count") {
+		oldInstanceCount, _ := d.GetChange("instance_count")
+		rollback = append(rollback, func() error {
+			if err := updateLoadBalancerAttributes(ctx, d, meta, &ah.LoadBalancerUpdateRequest{InstanceCount: oldInstanceCount.(int)}); err != nil {
+				return err
+			}
+			instanceCountUpdated = false
+			return nil
+		})
+
 		request := &ah.LoadBalancerUpdateRequest{
 			InstanceCount: d.Get("instance_count").(int),
 		}
@@ -394,29 +476,77 @@ func resourceAHLoadBalancerUpdate(ctx context.Context, d *schema.ResourceData, m
 		err := client.LoadBalancers.Update(ctx, d.Id(), request)
 
 		if err != nil {
-			return diag.Errorf(
-				"Error updating load balancer (%s): %s", d.Id(), err)
+			return failed(diag.Errorf(
+				"Error updating load balancer (%s): %s", d.Id(), err))
+		}
+		instanceCountUpdated = true
+
+		if diags := waitForReady(); diags != nil {
+			return diags
+		}
+	}
+
+	if d.HasChange("ip_address") {
+		oldIPs, newIPs := d.GetChange("ip_address")
+		rollback = append(rollback, func() error {
+			return restoreLoadBalancerChildren(ctx, d, meta, oldIPs.(*schema.Set).List(), flattenLoadBalancerIPAddresses, updateIPAddresses)
+		})
+
+		err := updateIPAddresses(ctx, d, meta, oldIPs.(*schema.Set).List(), newIPs.(*schema.Set).List())
+		if err != nil {
+			return failed(diag.FromErr(err))
+		}
+
+		if diags := waitForReady(); diags != nil {
+			return diags
 		}
 	}
 
 	if d.HasChange("private_network") {
-		err := updatePrivateNetworks(ctx, d, meta)
+		oldPNs, newPNs := d.GetChange("private_network")
+		rollback = append(rollback, func() error {
+			return restoreLoadBalancerChildren(ctx, d, meta, oldPNs.(*schema.Set).List(), flattenLoadBalancerPrivateNetworks, updatePrivateNetworks)
+		})
+
+		err := updatePrivateNetworks(ctx, d, meta, oldPNs.(*schema.Set).List(), newPNs.(*schema.Set).List())
 		if err != nil {
-			return diag.FromErr(err)
+			return failed(diag.FromErr(err))
+		}
+
+		if diags := waitForReady(); diags != nil {
+			return diags
 		}
 	}
 
 	if d.HasChange("backend_node") {
-		err := updateBackendNodes(ctx, d, meta)
+		oldBNs, newBNs := d.GetChange("backend_node")
+		rollback = append(rollback, func() error {
+			return restoreLoadBalancerChildren(ctx, d, meta, oldBNs.(*schema.Set).List(), flattenLoadBalancerBackendNodes, updateBackendNodes)
+		})
+
+		err := updateBackendNodes(ctx, d, meta, oldBNs.(*schema.Set).List(), newBNs.(*schema.Set).List())
 		if err != nil {
-			return diag.FromErr(err)
+			return failed(diag.FromErr(err))
+		}
+
+		if diags := waitForReady(); diags != nil {
+			return diags
 		}
 	}
 
 	if d.HasChange("health_check") {
-		err := updateHealthChecks(ctx, d, meta)
+		oldHCs, newHCs := d.GetChange("health_check")
+		rollback = append(rollback, func() error {
+			return restoreLoadBalancerChildren(ctx, d, meta, oldHCs.([]interface{}), flattenLoadBalancerHealthCheck, updateHealthChecks)
+		})
+
+		err := updateHealthChecks(ctx, d, meta, oldHCs.([]interface{}), newHCs.([]interface{}))
 		if err != nil {
-			return diag.FromErr(err)
+			return failed(diag.FromErr(err))
+		}
+
+		if diags := waitForReady(); diags != nil {
+			return diags
 		}
 	}
 
@@ -424,7 +554,7 @@ func resourceAHLoadBalancerUpdate(ctx context.Context, d *schema.ResourceData, m
 }
 
 func resourceAHLoadBalancerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.LoadBalancers.Delete(ctx, d.Id()); err != nil {
 		return diag.Errorf(
 			"Error deleting load balancer (%s): %s", d.Id(), err)
@@ -438,8 +568,108 @@ func resourceAHLoadBalancerDelete(ctx context.Context, d *schema.ResourceData, m
 	return nil
 }
 
+func flattenLoadBalancerIPAddresses(loadBalancer *ah.LoadBalancer) []interface{} {
+	ipsAddresses := make([]interface{}, len(loadBalancer.IPAddresses))
+	for i, ipAddress := range loadBalancer.IPAddresses {
+		item := make(map[string]interface{})
+		item["id"] = ipAddress.ID
+		item["type"] = ipAddress.Type
+		item["address"] = ipAddress.Address
+		item["state"] = ipAddress.State
+		ipsAddresses[i] = item
+	}
+	return ipsAddresses
+}
+
+func flattenLoadBalancerPrivateNetworks(loadBalancer *ah.LoadBalancer) []interface{} {
+	privateNetworks := make([]interface{}, len(loadBalancer.PrivateNetworks))
+	for i, pn := range loadBalancer.PrivateNetworks {
+		item := make(map[string]interface{})
+		item["id"] = pn.ID
+		item["state"] = pn.State
+		privateNetworks[i] = item
+
+	}
+	return privateNetworks
+}
+
+func flattenLoadBalancerBackendNodes(loadBalancer *ah.LoadBalancer) []interface{} {
+	backendNodes := make([]interface{}, len(loadBalancer.BackendNodes))
+	for i, bn := range loadBalancer.BackendNodes {
+		item := make(map[string]interface{})
+		item["id"] = bn.ID
+		item["cloud_server_id"] = bn.CloudServerID
+		backendNodes[i] = item
+
+	}
+	return backendNodes
+}
+
+func flattenLoadBalancerForwardingRules(loadBalancer *ah.LoadBalancer) []interface{} {
+	forwardingRules := make([]interface{}, len(loadBalancer.ForwardingRules))
+	for i, fr := range loadBalancer.ForwardingRules {
+		item := make(map[string]interface{})
+		item["id"] = fr.ID
+		item["request_protocol"] = fr.RequestProtocol
+		item["request_port"] = fr.RequestPort
+		item["communication_protocol"] = fr.CommunicationProtocol
+		item["communication_port"] = fr.CommunicationPort
+		forwardingRules[i] = item
+	}
+	return forwardingRules
+}
+
+func flattenLoadBalancerHealthCheck(loadBalancer *ah.LoadBalancer) []interface{} {
+	if loadBalancer.HealthCheck.ID == "" {
+		return nil
+	}
+	healthCheck := map[string]interface{}{
+		"id":                  loadBalancer.HealthCheck.ID,
+		"type":                loadBalancer.HealthCheck.Type,
+		"url":                 loadBalancer.HealthCheck.URL,
+		"interval":            loadBalancer.HealthCheck.Interval,
+		"timeout":             loadBalancer.HealthCheck.Timeout,
+		"unhealthy_threshold": loadBalancer.HealthCheck.UnhealthyThreshold,
+		"healthy_threshold":   loadBalancer.HealthCheck.HealthyThreshold,
+		"port":                loadBalancer.HealthCheck.Port,
+	}
+	return []interface{}{healthCheck}
+}
+
+// updateLoadBalancerAttributes changes attributes of the load balancer itself and waits for
+// the change to be applied.
+func updateLoadBalancerAttributes(ctx context.Context, d *schema.ResourceData, meta interface{}, request *ah.LoadBalancerUpdateRequest) error {
+	client := meta.(*CombinedConfig).ahClient()
+	if err := client.LoadBalancers.Update(ctx, d.Id(), request); err != nil {
+		return err
+	}
+	return waitForLoadBalancerStatus(ctx, []string{"updating"}, []string{"active"}, d, meta)
+}
+
+// restoreLoadBalancerChildren brings forwarding rules, private networks, backend nodes or the
+// health check back to their previous state. The current objects are read from the API, since
+// a failed step may have changed only some of them.
+func restoreLoadBalancerChildren(ctx context.Context, d *schema.ResourceData, meta interface{}, previous []interface{},
+	flatten func(*ah.LoadBalancer) []interface{},
+	update func(context.Context, *schema.ResourceData, interface{}, []interface{}, []interface{}) error) error {
+	client := meta.(*CombinedConfig).ahClient()
+
+	loadBalancer, err := client.LoadBalancers.Get(ctx, d.Id())
+	if err != nil {
+		return err
+	}
+	return update(ctx, d, meta, flatten(loadBalancer), previous)
+}
+
+// Delays before the first status check of load balancers and their child objects,
+// unit tests shorten them
+var (
+	loadBalancerStatusDelay = 20 * time.Second
+	loadBalancerStateDelay  = 5 * time.Second
+)
+
 func waitForLoadBalancerStatus(ctx context.Context, pendingStatuses, targetStatuses []string, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		lb, err := client.LoadBalancers.Get(context.Background(), d.Id())
@@ -451,7 +681,7 @@ func waitForLoadBalancerStatus(ctx context.Context, pendingStatuses, targetStatu
 	}
 
 	stateChangeConf := resource.StateChangeConf{
-		Delay:      20 * time.Second,
+		Delay:      loadBalancerStatusDelay,
 		Pending:    pendingStatuses,
 		Refresh:    stateRefreshFunc,
 		Target:     targetStatuses,
@@ -469,7 +699,7 @@ func waitForLoadBalancerStatus(ctx context.Context, pendingStatuses, targetStatu
 }
 
 func waitForLoadBalancerDestroy(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		lb, err := client.LoadBalancers.Get(context.Background(), d.Id())
@@ -502,10 +732,7 @@ func waitForLoadBalancerDestroy(ctx context.Context, d *schema.ResourceData, met
 	return nil
 }
 
-func updateForwardingRules(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
-	oldFRs, newFRs := d.GetChange("forwarding_rule")
-	oldFRsList := oldFRs.(*schema.Set).List()
-	newFRsList := newFRs.(*schema.Set).List()
+func updateForwardingRules(ctx context.Context, d *schema.ResourceData, meta interface{}, oldFRsList, newFRsList []interface{}) error {
 
 	frsToDelete := make(map[int]interface{}, len(oldFRsList))
 
@@ -518,7 +745,7 @@ func updateForwardingRules(ctx context.Context, d *schema.ResourceData, meta int
 		newFR := v.(map[string]interface{})
 		fr, ok := frsToDelete[newFR["request_port"].(int)]
 		if !ok {
-			if err := addForwardingRule(ctx, d, meta, newFR); err != nil {
+			if _, err := addForwardingRule(ctx, d, meta, d.Id(), newFR); err != nil {
 				return err
 			}
 		} else {
@@ -530,7 +757,7 @@ func updateForwardingRules(ctx context.Context, d *schema.ResourceData, meta int
 				oldFR["communication_protocol"].(string) != newFR["communication_protocol"].(string) ||
 				oldFR["communication_port"].(int) != newFR["communication_port"].(int) {
 
-				if err := updateForwardingRule(ctx, d, meta, oldFR["id"].(string), newFR); err != nil {
+				if err := updateForwardingRule(ctx, d, meta, d.Id(), oldFR["id"].(string), newFR); err != nil {
 					return err
 				}
 
@@ -543,7 +770,7 @@ func updateForwardingRules(ctx context.Context, d *schema.ResourceData, meta int
 
 	for _, v := range frsToDelete {
 		fr := v.(map[string]interface{})
-		if err := removeForwardingRule(ctx, d, meta, fr["id"].(string)); err != nil {
+		if err := removeForwardingRule(ctx, d, meta, d.Id(), fr["id"].(string)); err != nil {
 			return err
 		}
 	}
@@ -560,18 +787,18 @@ func makeFRCreateRequest(fr map[string]interface{}) *ah.LBForwardingRuleCreateRe
 	}
 }
 
-func addForwardingRule(ctx context.Context, d *schema.ResourceData, meta interface{}, fr map[string]interface{}) error {
-	client := meta.(*ah.APIClient)
+func addForwardingRule(ctx context.Context, d *schema.ResourceData, meta interface{}, lbID string, fr map[string]interface{}) (string, error) {
+	client := meta.(*CombinedConfig).ahClient()
 
 	frRequest := makeFRCreateRequest(fr)
 
-	newFR, err := client.LoadBalancers.CreateForwardingRule(ctx, d.Id(), frRequest)
+	newFR, err := client.LoadBalancers.CreateForwardingRule(ctx, lbID, frRequest)
 	if err != nil {
-		return fmt.Errorf("error creating forwarding rule %s", err)
+		return "", fmt.Errorf("error creating forwarding rule %s", err)
 	}
 
 	stateFunc := func() (result interface{}, state string, err error) {
-		fr, err := client.LoadBalancers.GetForwardingRule(ctx, d.Id(), newFR.ID)
+		fr, err := client.LoadBalancers.GetForwardingRule(ctx, lbID, newFR.ID)
 		if err != nil {
 			return nil, "", err
 		}
@@ -579,20 +806,20 @@ func addForwardingRule(ctx context.Context, d *schema.ResourceData, meta interfa
 	}
 
 	if err := waitForState(ctx, stateFunc, []string{"updating"}, []string{"active"}, d); err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return newFR.ID, nil
 }
 
-func removeForwardingRule(ctx context.Context, d *schema.ResourceData, meta interface{}, frID string) error {
-	client := meta.(*ah.APIClient)
-	if err := client.LoadBalancers.DeleteForwardingRule(ctx, d.Id(), frID); err != nil {
+func removeForwardingRule(ctx context.Context, d *schema.ResourceData, meta interface{}, lbID, frID string) error {
+	client := meta.(*CombinedConfig).ahClient()
+	if err := client.LoadBalancers.DeleteForwardingRule(ctx, lbID, frID); err != nil {
 		return err
 	}
 
 	stateFunc := func() (result interface{}, state string, err error) {
-		fr, err := client.LoadBalancers.GetForwardingRule(ctx, d.Id(), frID)
+		fr, err := client.LoadBalancers.GetForwardingRule(ctx, lbID, frID)
 		if err != nil {
 			if err == ah.ErrResourceNotFound {
 				return frID, "deleted", nil
@@ -609,12 +836,12 @@ func removeForwardingRule(ctx context.Context, d *schema.ResourceData, meta inte
 	return nil
 }
 
-func updateForwardingRule(ctx context.Context, d *schema.ResourceData, meta interface{}, frID string, fr map[string]interface{}) error {
-	if err := removeForwardingRule(ctx, d, meta, frID); err != nil {
+func updateForwardingRule(ctx context.Context, d *schema.ResourceData, meta interface{}, lbID, frID string, fr map[string]interface{}) error {
+	if err := removeForwardingRule(ctx, d, meta, lbID, frID); err != nil {
 		return err
 	}
 
-	if err := addForwardingRule(ctx, d, meta, fr); err != nil {
+	if _, err := addForwardingRule(ctx, d, meta, lbID, fr); err != nil {
 		return err
 	}
 	return nil
@@ -623,7 +850,7 @@ func updateForwardingRule(ctx context.Context, d *schema.ResourceData, meta inte
 func waitForState(ctx context.Context, stateFunc resource.StateRefreshFunc, pendingStatuses, targetStatuses []string, d *schema.ResourceData) error {
 
 	stateChangeConf := resource.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      loadBalancerStateDelay,
 		Pending:    pendingStatuses,
 		Refresh:    stateFunc,
 		Target:     targetStatuses,
@@ -640,10 +867,85 @@ func waitForState(ctx context.Context, stateFunc resource.StateRefreshFunc, pend
 	return nil
 }
 
-func updatePrivateNetworks(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
-	oldPNs, newPNs := d.GetChange("private_network")
-	oldPNsList := oldPNs.(*schema.Set).List()
-	newPNsList := newPNs.(*schema.Set).List()
+func updateIPAddresses(ctx context.Context, d *schema.ResourceData, meta interface{}, oldIPsList, newIPsList []interface{}) error {
+	ipsToRelease := make(map[string]bool, len(oldIPsList))
+
+	for _, v := range oldIPsList {
+		ip := v.(map[string]interface{})
+		ipsToRelease[ip["id"].(string)] = true
+	}
+
+	for _, v := range newIPsList {
+		newIP := v.(map[string]interface{})
+		ipID := newIP["id"].(string)
+		_, ok := ipsToRelease[ipID]
+		if !ok {
+			if err := addIPAddress(ctx, d, meta, ipID); err != nil {
+				return err
+			}
+		} else {
+			delete(ipsToRelease, ipID)
+		}
+
+	}
+
+	for ipID := range ipsToRelease {
+		if err := removeIPAddress(ctx, d, meta, ipID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addIPAddress(ctx context.Context, d *schema.ResourceData, meta interface{}, ipID string) error {
+	client := meta.(*CombinedConfig).ahClient()
+
+	_, err := client.LoadBalancers.AssignIPAddresses(ctx, d.Id(), []string{ipID})
+	if err != nil {
+		return fmt.Errorf("error assigning ip address %s: %s", ipID, err)
+	}
+
+	stateFunc := func() (result interface{}, state string, err error) {
+		ip, err := client.LoadBalancers.GetIPAddress(ctx, d.Id(), ipID)
+		if err != nil {
+			return nil, "", err
+		}
+		return ipID, ip.State, nil
+	}
+
+	if err := waitForState(ctx, stateFunc, []string{"updating"}, []string{"active"}, d); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func removeIPAddress(ctx context.Context, d *schema.ResourceData, meta interface{}, ipID string) error {
+	client := meta.(*CombinedConfig).ahClient()
+	if err := client.LoadBalancers.ReleaseIPAddress(ctx, d.Id(), ipID); err != nil {
+		return err
+	}
+
+	stateFunc := func() (result interface{}, state string, err error) {
+		ip, err := client.LoadBalancers.GetIPAddress(ctx, d.Id(), ipID)
+		if err != nil {
+			if err == ah.ErrResourceNotFound {
+				return ipID, "deleted", nil
+			}
+			return nil, "", err
+		}
+		return ipID, ip.State, nil
+	}
+
+	if err := waitForState(ctx, stateFunc, []string{"deleting"}, []string{"deleted"}, d); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updatePrivateNetworks(ctx context.Context, d *schema.ResourceData, meta interface{}, oldPNsList, newPNsList []interface{}) error {
 
 	pnsToDelete := make(map[string]bool, len(oldPNsList))
 
@@ -676,7 +978,7 @@ func updatePrivateNetworks(ctx context.Context, d *schema.ResourceData, meta int
 }
 
 func addPrivateNetwork(ctx context.Context, d *schema.ResourceData, meta interface{}, pnID string) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	_, err := client.LoadBalancers.ConnectPrivateNetworks(ctx, d.Id(), []string{pnID})
 	if err != nil {
@@ -699,7 +1001,7 @@ func addPrivateNetwork(ctx context.Context, d *schema.ResourceData, meta interfa
 }
 
 func removePrivateNetwork(ctx context.Context, d *schema.ResourceData, meta interface{}, pnID string) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.LoadBalancers.DisconnectPrivateNetwork(ctx, d.Id(), pnID); err != nil {
 		return err
 	}
@@ -722,10 +1024,7 @@ func removePrivateNetwork(ctx context.Context, d *schema.ResourceData, meta inte
 	return nil
 }
 
-func updateBackendNodes(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
-	oldBNs, newBNs := d.GetChange("backend_node")
-	oldBNsList := oldBNs.(*schema.Set).List()
-	newBNsList := newBNs.(*schema.Set).List()
+func updateBackendNodes(ctx context.Context, d *schema.ResourceData, meta interface{}, oldBNsList, newBNsList []interface{}) error {
 
 	bnsToDelete := make(map[string]bool, len(oldBNsList))
 
@@ -739,7 +1038,7 @@ func updateBackendNodes(ctx context.Context, d *schema.ResourceData, meta interf
 		bnID := newBN["id"].(string)
 		_, ok := bnsToDelete[bnID]
 		if !ok {
-			if err := addBackendNode(ctx, d, meta, newBN); err != nil {
+			if _, err := addBackendNode(ctx, d, meta, d.Id(), newBN["cloud_server_id"].(string)); err != nil {
 				return err
 			}
 		} else {
@@ -749,7 +1048,7 @@ func updateBackendNodes(ctx context.Context, d *schema.ResourceData, meta interf
 	}
 
 	for bnID, _ := range bnsToDelete {
-		if err := removeBackendNode(ctx, d, meta, bnID); err != nil {
+		if err := removeBackendNode(ctx, d, meta, d.Id(), bnID); err != nil {
 			return err
 		}
 	}
@@ -757,18 +1056,18 @@ func updateBackendNodes(ctx context.Context, d *schema.ResourceData, meta interf
 	return nil
 }
 
-func addBackendNode(ctx context.Context, d *schema.ResourceData, meta interface{}, backendNode map[string]interface{}) error {
-	client := meta.(*ah.APIClient)
+func addBackendNode(ctx context.Context, d *schema.ResourceData, meta interface{}, lbID, cloudServerID string) (string, error) {
+	client := meta.(*CombinedConfig).ahClient()
 
-	bns, err := client.LoadBalancers.AddBackendNodes(ctx, d.Id(), []string{backendNode["cloud_server_id"].(string)})
+	bns, err := client.LoadBalancers.AddBackendNodes(ctx, lbID, []string{cloudServerID})
 	if err != nil {
-		return fmt.Errorf("error connecting backend node %s", err)
+		return "", fmt.Errorf("error connecting backend node %s", err)
 	}
 
 	bnID := bns[0].ID
 
 	stateFunc := func() (result interface{}, state string, err error) {
-		bn, err := client.LoadBalancers.GetBackendNode(ctx, d.Id(), bnID)
+		bn, err := client.LoadBalancers.GetBackendNode(ctx, lbID, bnID)
 		if err != nil {
 			return nil, "", err
 		}
@@ -776,20 +1075,20 @@ func addBackendNode(ctx context.Context, d *schema.ResourceData, meta interface{
 	}
 
 	if err := waitForState(ctx, stateFunc, []string{"updating"}, []string{"active"}, d); err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return bnID, nil
 }
 
-func removeBackendNode(ctx context.Context, d *schema.ResourceData, meta interface{}, bnID string) error {
-	client := meta.(*ah.APIClient)
-	if err := client.LoadBalancers.DeleteBackendNode(ctx, d.Id(), bnID); err != nil {
+func removeBackendNode(ctx context.Context, d *schema.ResourceData, meta interface{}, lbID, bnID string) error {
+	client := meta.(*CombinedConfig).ahClient()
+	if err := client.LoadBalancers.DeleteBackendNode(ctx, lbID, bnID); err != nil {
 		return err
 	}
 
 	stateFunc := func() (result interface{}, state string, err error) {
-		fr, err := client.LoadBalancers.GetBackendNode(ctx, d.Id(), bnID)
+		fr, err := client.LoadBalancers.GetBackendNode(ctx, lbID, bnID)
 		if err != nil {
 			if err == ah.ErrResourceNotFound {
 				return bnID, "deleted", nil
@@ -806,14 +1105,14 @@ func removeBackendNode(ctx context.Context, d *schema.ResourceData, meta interfa
 	return nil
 }
 
-func updateHealthChecks(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
-	oldHCs, newHCs := d.GetChange("health_check")
-	oldHCsList := oldHCs.([]interface{})
-	newHCsList := newHCs.([]interface{})
+func updateHealthChecks(ctx context.Context, d *schema.ResourceData, meta interface{}, oldHCsList, newHCsList []interface{}) error {
+	if len(oldHCsList) == 0 && len(newHCsList) == 0 {
+		return nil
+	}
 
 	if len(oldHCsList) == 0 {
 		hc := newHCsList[0].(map[string]interface{})
-		if err := addHealthCheck(ctx, d, meta, hc); err != nil {
+		if _, err := addHealthCheck(ctx, d, meta, d.Id(), hc); err != nil {
 			return err
 		}
 		return nil
@@ -821,7 +1120,7 @@ func updateHealthChecks(ctx context.Context, d *schema.ResourceData, meta interf
 
 	if len(newHCsList) == 0 {
 		hc := oldHCsList[0].(map[string]interface{})
-		if err := removeHealthCheck(ctx, d, meta, hc["id"].(string)); err != nil {
+		if err := removeHealthCheck(ctx, d, meta, d.Id(), hc["id"].(string)); err != nil {
 			return err
 		}
 		return nil
@@ -830,7 +1129,7 @@ func updateHealthChecks(ctx context.Context, d *schema.ResourceData, meta interf
 	oldHC := oldHCsList[0].(map[string]interface{})
 	hcID := oldHC["id"].(string)
 	hc := newHCsList[0].(map[string]interface{})
-	if err := updateHealthCheck(ctx, d, meta, hcID, hc); err != nil {
+	if err := updateHealthCheck(ctx, d, meta, d.Id(), hcID, hc); err != nil {
 		return err
 	}
 	return nil
@@ -860,18 +1159,18 @@ func makeHCCreateRequest(hc map[string]interface{}) ah.LBHealthCheckCreateReques
 	return hcRequest
 }
 
-func addHealthCheck(ctx context.Context, d *schema.ResourceData, meta interface{}, hc map[string]interface{}) error {
-	client := meta.(*ah.APIClient)
+func addHealthCheck(ctx context.Context, d *schema.ResourceData, meta interface{}, lbID string, hc map[string]interface{}) (string, error) {
+	client := meta.(*CombinedConfig).ahClient()
 
 	hcRequest := makeHCCreateRequest(hc)
 
-	newHC, err := client.LoadBalancers.CreateHealthCheck(ctx, d.Id(), &hcRequest)
+	newHC, err := client.LoadBalancers.CreateHealthCheck(ctx, lbID, &hcRequest)
 	if err != nil {
-		return fmt.Errorf("error creating health check: %s", err)
+		return "", fmt.Errorf("error creating health check: %s", err)
 	}
 
 	stateFunc := func() (result interface{}, state string, err error) {
-		fr, err := client.LoadBalancers.GetHealthCheck(ctx, d.Id(), newHC.ID)
+		fr, err := client.LoadBalancers.GetHealthCheck(ctx, lbID, newHC.ID)
 		if err != nil {
 			return nil, "", err
 		}
@@ -879,20 +1178,20 @@ func addHealthCheck(ctx context.Context, d *schema.ResourceData, meta interface{
 	}
 
 	if err := waitForState(ctx, stateFunc, []string{"creating", "defined"}, []string{"active"}, d); err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return newHC.ID, nil
 }
 
-func removeHealthCheck(ctx context.Context, d *schema.ResourceData, meta interface{}, hcID string) error {
-	client := meta.(*ah.APIClient)
-	if err := client.LoadBalancers.DeleteHealthCheck(ctx, d.Id(), hcID); err != nil {
+func removeHealthCheck(ctx context.Context, d *schema.ResourceData, meta interface{}, lbID, hcID string) error {
+	client := meta.(*CombinedConfig).ahClient()
+	if err := client.LoadBalancers.DeleteHealthCheck(ctx, lbID, hcID); err != nil {
 		return err
 	}
 
 	stateFunc := func() (result interface{}, state string, err error) {
-		hc, err := client.LoadBalancers.GetHealthCheck(ctx, d.Id(), hcID)
+		hc, err := client.LoadBalancers.GetHealthCheck(ctx, lbID, hcID)
 		if err != nil {
 			if err == ah.ErrResourceNotFound {
 				return hcID, "deleted", nil
@@ -909,8 +1208,8 @@ func removeHealthCheck(ctx context.Context, d *schema.ResourceData, meta interfa
 	return nil
 }
 
-func updateHealthCheck(ctx context.Context, d *schema.ResourceData, meta interface{}, hcID string, hc map[string]interface{}) error {
-	client := meta.(*ah.APIClient)
+func updateHealthCheck(ctx context.Context, d *schema.ResourceData, meta interface{}, lbID, hcID string, hc map[string]interface{}) error {
+	client := meta.(*CombinedConfig).ahClient()
 
 	hcRequest := &ah.LBHealthCheckUpdateRequest{
 		Type: hc["type"].(string),
@@ -933,12 +1232,12 @@ func updateHealthCheck(ctx context.Context, d *schema.ResourceData, meta interfa
 		hcRequest.HealthyThreshold = healthyThreshold.(int)
 	}
 
-	if err := client.LoadBalancers.UpdateHealthCheck(ctx, d.Id(), hcID, hcRequest); err != nil {
+	if err := client.LoadBalancers.UpdateHealthCheck(ctx, lbID, hcID, hcRequest); err != nil {
 		return fmt.Errorf("error updating health check %s", err)
 	}
 
 	stateFunc := func() (result interface{}, state string, err error) {
-		fr, err := client.LoadBalancers.GetHealthCheck(ctx, d.Id(), hcID)
+		fr, err := client.LoadBalancers.GetHealthCheck(ctx, lbID, hcID)
 		if err != nil {
 			return nil, "", err
 		}
@@ -0,0 +1,116 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceAHLoadBalancerBackendNode() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAHLoadBalancerBackendNodeCreate,
+		ReadContext:   resourceAHLoadBalancerBackendNodeRead,
+		DeleteContext: resourceAHLoadBalancerBackendNodeDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"load_balancer_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"cloud_server_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceAHLoadBalancerBackendNodeCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	lbID := d.Get("load_balancer_id").(string)
+
+	locks := &meta.(*CombinedConfig).loadBalancerLocks
+	locks.Lock(lbID)
+	defer locks.Unlock(lbID)
+
+	bnID, err := addBackendNode(ctx, d, meta, lbID, d.Get("cloud_server_id").(string))
+	if err != nil {
+		return diag.Errorf("Error adding backend node to load balancer (%s): %s", lbID, err)
+	}
+	d.SetId(loadBalancerChildID(lbID, bnID))
+
+	return resourceAHLoadBalancerBackendNodeRead(ctx, d, meta)
+}
+
+func resourceAHLoadBalancerBackendNodeRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*CombinedConfig).ahClient()
+
+	lbID, bnID, err := parseLoadBalancerChildID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	bn, err := client.LoadBalancers.GetBackendNode(ctx, lbID, bnID)
+	if err != nil {
+		if err == ah.ErrResourceNotFound {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	d.Set("load_balancer_id", lbID)
+	d.Set("cloud_server_id", bn.CloudServerID)
+	d.Set("state", bn.State)
+
+	return nil
+}
+
+func resourceAHLoadBalancerBackendNodeDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	lbID, bnID, err := parseLoadBalancerChildID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	locks := &meta.(*CombinedConfig).loadBalancerLocks
+	locks.Lock(lbID)
+	defer locks.Unlock(lbID)
+
+	if err := removeBackendNode(ctx, d, meta, lbID, bnID); err != nil {
+		if err == ah.ErrResourceNotFound {
+			return nil
+		}
+		return diag.Errorf("Error removing backend node (%s) from load balancer (%s): %s", bnID, lbID, err)
+	}
+
+	return nil
+}
+
+// loadBalancerChildID builds IDs of objects that belong to a load balancer, like backend nodes
+// and forwarding rules.
+func loadBalancerChildID(lbID, childID string) string {
+	return fmt.Sprintf("%s/%s", lbID, childID)
+}
+
+func parseLoadBalancerChildID(id string) (string, string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected ID format (%s), expected <load_balancer_id>/<id>", id)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,119 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccAHLoadBalancerBackendNode_Basic(t *testing.T) {
+	name := fmt.Sprintf("test-%s", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHLoadBalancerBackendNodeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: datasourceConfigBasic() + testAccCheckAHLoadBalancerBackendNodeConfig_Basic(name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("ah_load_balancer_backend_node.web", "load_balancer_id", "ah_load_balancer.web", "id"),
+					resource.TestCheckResourceAttrPair("ah_load_balancer_backend_node.web", "cloud_server_id", "ah_cloud_server.web", "id"),
+					resource.TestCheckResourceAttr("ah_load_balancer_backend_node.web", "state", "active"),
+				),
+			},
+			{
+				ResourceName:      "ah_load_balancer_backend_node.web",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// The inline block is computed, so the node registered above doesn't cause a diff
+				Config:   datasourceConfigBasic() + testAccCheckAHLoadBalancerBackendNodeConfig_Basic(name),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestParseLoadBalancerChildID(t *testing.T) {
+	lbID, childID, err := parseLoadBalancerChildID(loadBalancerChildID("lb-id", "node-id"))
+	if err != nil || lbID != "lb-id" || childID != "node-id" {
+		t.Fatalf("unexpected result: %s, %s, %v", lbID, childID, err)
+	}
+
+	for _, id := range []string{"", "lb-id", "lb-id/", "/node-id", "lb-id/node-id/extra"} {
+		if _, _, err := parseLoadBalancerChildID(id); err == nil {
+			t.Errorf("expected error for ID %q", id)
+		}
+	}
+}
+
+func testAccCheckAHLoadBalancerBackendNodeDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "ah_load_balancer_backend_node" {
+			continue
+		}
+
+		lbID, bnID, err := parseLoadBalancerChildID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.LoadBalancers.GetBackendNode(context.Background(), lbID, bnID)
+		if err == nil {
+			return fmt.Errorf("Backend node still exists")
+		}
+		if err != ah.ErrResourceNotFound {
+			return err
+		}
+	}
+
+	return testAccCheckAHLoadBalancerDestroy(s)
+}
+
+func testAccCheckAHLoadBalancerBackendNodeConfig_Basic(name string) string {
+	return fmt.Sprintf(`
+     resource "ah_cloud_server" "web" {
+	   name = "cs-%[1]s"
+	   datacenter = "%s"
+	   image = "${data.ah_cloud_images.test.images.0.id}"
+	   plan = "%s"
+	 }
+
+     resource "ah_private_network" "test" {
+	   ip_range = "10.0.0.0/24"
+	   name = "Test Private Network"
+	 }
+
+     resource "ah_private_network_connection" "example" {
+	   cloud_server_id = ah_cloud_server.web.id
+	   private_network_id = ah_private_network.test.id
+	 }
+
+	 resource "ah_load_balancer" "web" {
+	   name = "%[1]s"
+	   datacenter = "%s"
+	   balancing_algorithm = "round_robin"
+	   instance_count = 1
+       create_public_ip_address = false
+       private_network {
+         id = ah_private_network.test.id
+       }
+	 }
+
+	 resource "ah_load_balancer_backend_node" "web" {
+	   depends_on = [
+	     ah_private_network_connection.example,
+	   ]
+	   load_balancer_id = ah_load_balancer.web.id
+	   cloud_server_id = ah_cloud_server.web.id
+	 }`, name, DatacenterName, VpsPlanName, DatacenterName)
+}
@@ -0,0 +1,129 @@
+package ah
+
+import (
+	"context"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceAHLoadBalancerForwardingRule() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAHLoadBalancerForwardingRuleCreate,
+		ReadContext:   resourceAHLoadBalancerForwardingRuleRead,
+		DeleteContext: resourceAHLoadBalancerForwardingRuleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: loadBalancerForwardingRuleResourceDiff,
+
+		// Forwarding rules can't be updated, every change recreates the rule
+		Schema: map[string]*schema.Schema{
+			"load_balancer_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"request_protocol": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(loadBalancerProtocols, false),
+			},
+			"request_port": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"communication_protocol": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(loadBalancerProtocols, false),
+			},
+			"communication_port": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceAHLoadBalancerForwardingRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	lbID := d.Get("load_balancer_id").(string)
+
+	locks := &meta.(*CombinedConfig).loadBalancerLocks
+	locks.Lock(lbID)
+	defer locks.Unlock(lbID)
+
+	fr := map[string]interface{}{
+		"request_protocol":       d.Get("request_protocol"),
+		"request_port":           d.Get("request_port"),
+		"communication_protocol": d.Get("communication_protocol"),
+		"communication_port":     d.Get("communication_port"),
+	}
+	frID, err := addForwardingRule(ctx, d, meta, lbID, fr)
+	if err != nil {
+		return diag.Errorf("Error adding forwarding rule to load balancer (%s): %s", lbID, err)
+	}
+	d.SetId(loadBalancerChildID(lbID, frID))
+
+	return resourceAHLoadBalancerForwardingRuleRead(ctx, d, meta)
+}
+
+func resourceAHLoadBalancerForwardingRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*CombinedConfig).ahClient()
+
+	lbID, frID, err := parseLoadBalancerChildID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	fr, err := client.LoadBalancers.GetForwardingRule(ctx, lbID, frID)
+	if err != nil {
+		if err == ah.ErrResourceNotFound {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	d.Set("load_balancer_id", lbID)
+	d.Set("request_protocol", fr.RequestProtocol)
+	d.Set("request_port", fr.RequestPort)
+	d.Set("communication_protocol", fr.CommunicationProtocol)
+	d.Set("communication_port", fr.CommunicationPort)
+	d.Set("state", fr.State)
+
+	return nil
+}
+
+func resourceAHLoadBalancerForwardingRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	lbID, frID, err := parseLoadBalancerChildID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	locks := &meta.(*CombinedConfig).loadBalancerLocks
+	locks.Lock(lbID)
+	defer locks.Unlock(lbID)
+
+	if err := removeForwardingRule(ctx, d, meta, lbID, frID); err != nil {
+		if err == ah.ErrResourceNotFound {
+			return nil
+		}
+		return diag.Errorf("Error removing forwarding rule (%s) from load balancer (%s): %s", frID, lbID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccAHLoadBalancerForwardingRule_Basic(t *testing.T) {
+	name := fmt.Sprintf("test-%s", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHLoadBalancerForwardingRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckAHLoadBalancerForwardingRuleConfig(name, 80),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("ah_load_balancer_forwarding_rule.web", "load_balancer_id", "ah_load_balancer.web", "id"),
+					resource.TestCheckResourceAttr("ah_load_balancer_forwarding_rule.web", "request_protocol", "tcp"),
+					resource.TestCheckResourceAttr("ah_load_balancer_forwarding_rule.web", "request_port", "80"),
+					resource.TestCheckResourceAttr("ah_load_balancer_forwarding_rule.web", "communication_protocol", "tcp"),
+					resource.TestCheckResourceAttr("ah_load_balancer_forwarding_rule.web", "communication_port", "8080"),
+					resource.TestCheckResourceAttr("ah_load_balancer_forwarding_rule.web", "state", "active"),
+				),
+			},
+			{
+				ResourceName:      "ah_load_balancer_forwarding_rule.web",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccCheckAHLoadBalancerForwardingRuleConfig(name, 81),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_load_balancer_forwarding_rule.web", "request_port", "81"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckAHLoadBalancerForwardingRuleDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "ah_load_balancer_forwarding_rule" {
+			continue
+		}
+
+		lbID, frID, err := parseLoadBalancerChildID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.LoadBalancers.GetForwardingRule(context.Background(), lbID, frID)
+		if err == nil {
+			return fmt.Errorf("Forwarding rule still exists")
+		}
+		if err != ah.ErrResourceNotFound {
+			return err
+		}
+	}
+
+	return testAccCheckAHLoadBalancerDestroy(s)
+}
+
+func testAccCheckAHLoadBalancerForwardingRuleConfig(name string, requestPort int) string {
+	return fmt.Sprintf(`
+	 resource "ah_load_balancer" "web" {
+	   name = "%s"
+	   datacenter = "%s"
+	   balancing_algorithm = "round_robin"
+	   instance_count = 1
+       create_public_ip_address = false
+	 }
+
+	 resource "ah_load_balancer_forwarding_rule" "web" {
+	   load_balancer_id = ah_load_balancer.web.id
+	   request_protocol = "tcp"
+	   request_port = %d
+	   communication_protocol = "tcp"
+	   communication_port = 8080
+	 }`, name, DatacenterName, requestPort)
+}
@@ -0,0 +1,168 @@
+package ah
+
+import (
+	"context"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceAHLoadBalancerHealthCheck() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAHLoadBalancerHealthCheckCreate,
+		ReadContext:   resourceAHLoadBalancerHealthCheckRead,
+		UpdateContext: resourceAHLoadBalancerHealthCheckUpdate,
+		DeleteContext: resourceAHLoadBalancerHealthCheckDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: loadBalancerHealthCheckResourceDiff,
+
+		Schema: map[string]*schema.Schema{
+			"load_balancer_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"port": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"interval": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"timeout": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"unhealthy_threshold": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"healthy_threshold": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// loadBalancerHealthCheckAttrs returns the health check arguments in the form of the inline
+// health_check block of ah_load_balancer.
+func loadBalancerHealthCheckAttrs(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"type":                d.Get("type"),
+		"port":                d.Get("port"),
+		"url":                 d.Get("url"),
+		"interval":            d.Get("interval"),
+		"timeout":             d.Get("timeout"),
+		"unhealthy_threshold": d.Get("unhealthy_threshold"),
+		"healthy_threshold":   d.Get("healthy_threshold"),
+	}
+}
+
+func resourceAHLoadBalancerHealthCheckCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	lbID := d.Get("load_balancer_id").(string)
+
+	locks := &meta.(*CombinedConfig).loadBalancerLocks
+	locks.Lock(lbID)
+	defer locks.Unlock(lbID)
+
+	hcID, err := addHealthCheck(ctx, d, meta, lbID, loadBalancerHealthCheckAttrs(d))
+	if err != nil {
+		return diag.Errorf("Error adding health check to load balancer (%s): %s", lbID, err)
+	}
+	d.SetId(loadBalancerChildID(lbID, hcID))
+
+	return resourceAHLoadBalancerHealthCheckRead(ctx, d, meta)
+}
+
+func resourceAHLoadBalancerHealthCheckRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*CombinedConfig).ahClient()
+
+	lbID, hcID, err := parseLoadBalancerChildID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	hc, err := client.LoadBalancers.GetHealthCheck(ctx, lbID, hcID)
+	if err != nil {
+		if err == ah.ErrResourceNotFound {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	d.Set("load_balancer_id", lbID)
+	d.Set("type", hc.Type)
+	d.Set("port", hc.Port)
+	d.Set("url", hc.URL)
+	d.Set("interval", hc.Interval)
+	d.Set("timeout", hc.Timeout)
+	d.Set("unhealthy_threshold", hc.UnhealthyThreshold)
+	d.Set("healthy_threshold", hc.HealthyThreshold)
+	d.Set("state", hc.State)
+
+	return nil
+}
+
+func resourceAHLoadBalancerHealthCheckUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	lbID, hcID, err := parseLoadBalancerChildID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	locks := &meta.(*CombinedConfig).loadBalancerLocks
+	locks.Lock(lbID)
+	defer locks.Unlock(lbID)
+
+	if err := updateHealthCheck(ctx, d, meta, lbID, hcID, loadBalancerHealthCheckAttrs(d)); err != nil {
+		return diag.Errorf("Error updating health check (%s) of load balancer (%s): %s", hcID, lbID, err)
+	}
+
+	return resourceAHLoadBalancerHealthCheckRead(ctx, d, meta)
+}
+
+func resourceAHLoadBalancerHealthCheckDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	lbID, hcID, err := parseLoadBalancerChildID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	locks := &meta.(*CombinedConfig).loadBalancerLocks
+	locks.Lock(lbID)
+	defer locks.Unlock(lbID)
+
+	if err := removeHealthCheck(ctx, d, meta, lbID, hcID); err != nil {
+		if err == ah.ErrResourceNotFound {
+			return nil
+		}
+		return diag.Errorf("Error removing health check (%s) from load balancer (%s): %s", hcID, lbID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package ah
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccAHLoadBalancerHealthCheck_Basic(t *testing.T) {
+	name := fmt.Sprintf("test-%s", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHLoadBalancerHealthCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckAHLoadBalancerHealthCheckConfig(name, 9090),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("ah_load_balancer_health_check.web", "load_balancer_id", "ah_load_balancer.web", "id"),
+					resource.TestCheckResourceAttr("ah_load_balancer_health_check.web", "type", "tcp"),
+					resource.TestCheckResourceAttr("ah_load_balancer_health_check.web", "port", "9090"),
+					resource.TestCheckResourceAttrSet("ah_load_balancer_health_check.web", "interval"),
+					resource.TestCheckResourceAttrSet("ah_load_balancer_health_check.web", "timeout"),
+				),
+			},
+			{
+				ResourceName:      "ah_load_balancer_health_check.web",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccCheckAHLoadBalancerHealthCheckConfig(name, 9091),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_load_balancer_health_check.web", "port", "9091"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckAHLoadBalancerHealthCheckDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "ah_load_balancer_health_check" {
+			continue
+		}
+
+		lbID, hcID, err := parseLoadBalancerChildID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.LoadBalancers.GetHealthCheck(context.Background(), lbID, hcID)
+		if err == nil {
+			return fmt.Errorf("Health check still exists")
+		}
+		if err != ah.ErrResourceNotFound {
+			return err
+		}
+	}
+
+	return testAccCheckAHLoadBalancerDestroy(s)
+}
+
+func testAccCheckAHLoadBalancerHealthCheckConfig(name string, port int) string {
+	return fmt.Sprintf(`
+	 resource "ah_load_balancer" "web" {
+	   name = "%s"
+	   datacenter = "%s"
+	   balancing_algorithm = "round_robin"
+	   instance_count = 1
+       create_public_ip_address = false
+	 }
+
+	 resource "ah_load_balancer_health_check" "web" {
+	   load_balancer_id = ah_load_balancer.web.id
+	   type = "tcp"
+	   port = %d
+	 }`, name, DatacenterName, port)
+}
@@ -2,11 +2,17 @@ package ah
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/advancedhosting/advancedhosting-api-go/ah"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -77,7 +83,7 @@ func TestAccAHLoadBalancer_DeleteForwardingRule(t *testing.T) {
 				),
 			},
 			{
-				Config: testAccCheckAHLoadBalancerConfig_Empty(name),
+				Config: testAccCheckAHLoadBalancerConfig_Cleared(name),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("ah_load_balancer.web", "forwarding_rule.#", "0"),
 				),
@@ -135,6 +141,57 @@ func TestAccAHLoadBalancer_ChangeBalancingAlgorithm(t *testing.T) {
 	})
 }
 
+func TestAccAHLoadBalancer_ProxyProtocol(t *testing.T) {
+	name := fmt.Sprintf("test-%s", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHLoadBalancerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckAHLoadBalancerConfig_ProxyProtocol(name, "v1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_load_balancer.web", "proxy_protocol", "v1"),
+				),
+			},
+			{
+				Config: testAccCheckAHLoadBalancerConfig_ProxyProtocol(name, "v2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_load_balancer.web", "proxy_protocol", "v2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAHLoadBalancer_AssignIPAddress(t *testing.T) {
+	name := fmt.Sprintf("test-%s", acctest.RandString(10))
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckAHLoadBalancerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckAHLoadBalancerConfig_WithIP(name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_load_balancer.web", "ip_address.#", "1"),
+					resource.TestCheckTypeSetElemAttrPair("ah_load_balancer.web", "ip_address.*.id", "ah_ip.test", "id"),
+					resource.TestCheckResourceAttr("ah_load_balancer.web", "state", "active"),
+				),
+			},
+			{
+				Config: testAccCheckAHLoadBalancerConfig_WithoutIP(name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ah_load_balancer.web", "ip_address.#", "0"),
+					resource.TestCheckResourceAttr("ah_load_balancer.web", "state", "active"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccAHLoadBalancer_AddForwardingRule(t *testing.T) {
 	name := fmt.Sprintf("test-%s", acctest.RandString(10))
 
@@ -312,7 +369,7 @@ func TestAccAHLoadBalancer_DeleteHealthCheck(t *testing.T) {
 				),
 			},
 			{
-				Config: testAccCheckAHLoadBalancerConfig_Empty(name),
+				Config: testAccCheckAHLoadBalancerConfig_Cleared(name),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("ah_load_balancer.web", "health_check.#", "0"),
 				),
@@ -347,8 +404,265 @@ func TestAccAHLoadBalancer_UpdateHealthCheck(t *testing.T) {
 	})
 }
 
+// fakeLoadBalancersAPI keeps a single active load balancer for unit tests. It records the
+// calls that change it and fails the call named by failOn.
+type fakeLoadBalancersAPI struct {
+	ah.LoadBalancersAPI
+	loadBalancer ah.LoadBalancer
+	failOn       string
+	calls        []string
+}
+
+func (f *fakeLoadBalancersAPI) call(name string) error {
+	f.calls = append(f.calls, name)
+	if name == f.failOn {
+		return fmt.Errorf("%s failed", name)
+	}
+	return nil
+}
+
+func (f *fakeLoadBalancersAPI) Get(ctx context.Context, lbID string) (*ah.LoadBalancer, error) {
+	lb := f.loadBalancer
+	lb.IPAddresses = append([]ah.LBIPAddress(nil), f.loadBalancer.IPAddresses...)
+	return &lb, nil
+}
+
+func (f *fakeLoadBalancersAPI) Update(ctx context.Context, lbID string, request *ah.LoadBalancerUpdateRequest) error {
+	switch {
+	case request.Name != "":
+		if err := f.call("update name " + request.Name); err != nil {
+			return err
+		}
+		f.loadBalancer.Name = request.Name
+	case request.InstanceCount != 0:
+		if err := f.call(fmt.Sprintf("update instance_count %d", request.InstanceCount)); err != nil {
+			return err
+		}
+		f.loadBalancer.InstanceCount = request.InstanceCount
+	default:
+		return errors.New("unexpected load balancer update")
+	}
+	return nil
+}
+
+func (f *fakeLoadBalancersAPI) AssignIPAddresses(ctx context.Context, lbID string, ipIDs []string) ([]ah.LBIPAddress, error) {
+	var ipAddresses []ah.LBIPAddress
+	for _, ipID := range ipIDs {
+		if err := f.call("assign " + ipID); err != nil {
+			return nil, err
+		}
+		ipAddress := ah.LBIPAddress{ID: ipID, Type: "public", State: "active"}
+		f.loadBalancer.IPAddresses = append(f.loadBalancer.IPAddresses, ipAddress)
+		ipAddresses = append(ipAddresses, ipAddress)
+	}
+	return ipAddresses, nil
+}
+
+func (f *fakeLoadBalancersAPI) ReleaseIPAddress(ctx context.Context, lbID, ipID string) error {
+	if err := f.call("release " + ipID); err != nil {
+		return err
+	}
+	for i, ipAddress := range f.loadBalancer.IPAddresses {
+		if ipAddress.ID == ipID {
+			f.loadBalancer.IPAddresses = append(f.loadBalancer.IPAddresses[:i], f.loadBalancer.IPAddresses[i+1:]...)
+			return nil
+		}
+	}
+	return ah.ErrResourceNotFound
+}
+
+func (f *fakeLoadBalancersAPI) GetIPAddress(ctx context.Context, lbID, ipID string) (*ah.LBIPAddress, error) {
+	for _, ipAddress := range f.loadBalancer.IPAddresses {
+		if ipAddress.ID == ipID {
+			return &ipAddress, nil
+		}
+	}
+	return nil, ah.ErrResourceNotFound
+}
+
+// testLoadBalancerUpdate refreshes a load balancer from the fake API and applies config to it.
+func testLoadBalancerUpdate(t *testing.T, meta *CombinedConfig, instanceCount int, config map[string]interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	t.Helper()
+	loadBalancerStatusDelay, loadBalancerStateDelay = 0, 0
+
+	r := resourceAHLoadBalancer()
+	state := &terraform.InstanceState{
+		ID: "lb-id",
+		Attributes: map[string]string{
+			"id":                       "lb-id",
+			"datacenter":               DatacenterName,
+			"create_public_ip_address": "true",
+			"rollback_on_failure":      "false",
+			"instance_count":           strconv.Itoa(instanceCount),
+		},
+	}
+	state, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	state.RawConfig = testResourceConfig(t, r, config)
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff == nil {
+		return state, nil
+	}
+	return r.Apply(context.Background(), state, diff, meta)
+}
+
+func testLoadBalancerIPAddressIDs(state *terraform.InstanceState) []string {
+	var ipIDs []string
+	for k, v := range state.Attributes {
+		if strings.HasPrefix(k, "ip_address.") && strings.HasSuffix(k, ".id") {
+			ipIDs = append(ipIDs, v)
+		}
+	}
+	sort.Strings(ipIDs)
+	return ipIDs
+}
+
+func TestResourceAHLoadBalancer_FailedUpdate(t *testing.T) {
+	lbAPI := &fakeLoadBalancersAPI{
+		loadBalancer: ah.LoadBalancer{ID: "lb-id", Name: "old", State: "active", BalancingAlgorithm: "round_robin", InstanceCount: 1},
+		failOn:       "update instance_count 2",
+	}
+	meta := testFakeMeta()
+	meta.client.LoadBalancers = lbAPI
+
+	config := map[string]interface{}{
+		"name":           "new",
+		"datacenter":     DatacenterName,
+		"instance_count": 2,
+	}
+	newState, diags := testLoadBalancerUpdate(t, meta, 1, config)
+	if !diags.HasError() {
+		t.Fatal("expected update to fail")
+	}
+
+	expectedCalls := []string{"update name new", "update instance_count 2"}
+	if !reflect.DeepEqual(lbAPI.calls, expectedCalls) {
+		t.Fatalf("expected calls %v, got %v", expectedCalls, lbAPI.calls)
+	}
+	// The completed rename is recorded, the failed instance_count change isn't
+	if newState.Attributes["name"] != "new" || newState.Attributes["instance_count"] != "1" {
+		t.Fatalf("expected name new and instance_count 1 in state, got %s and %s",
+			newState.Attributes["name"], newState.Attributes["instance_count"])
+	}
+}
+
+func TestResourceAHLoadBalancer_RollbackOnFailure(t *testing.T) {
+	lbAPI := &fakeLoadBalancersAPI{
+		loadBalancer: ah.LoadBalancer{
+			ID:                 "lb-id",
+			Name:               "old",
+			State:              "active",
+			BalancingAlgorithm: "round_robin",
+			InstanceCount:      1,
+			IPAddresses:        []ah.LBIPAddress{{ID: "ip-1", Type: "public", State: "active"}},
+		},
+		failOn: "release ip-1",
+	}
+	meta := testFakeMeta()
+	meta.client.LoadBalancers = lbAPI
+
+	config := map[string]interface{}{
+		"name":                "new",
+		"datacenter":          DatacenterName,
+		"instance_count":      2,
+		"rollback_on_failure": true,
+		"ip_address":          []interface{}{map[string]interface{}{"id": "ip-2"}},
+	}
+	newState, diags := testLoadBalancerUpdate(t, meta, 1, config)
+	if !diags.HasError() {
+		t.Fatal("expected update to fail")
+	}
+
+	// The ip address step is reconciled from the API, then the completed steps are undone in reverse
+	expectedCalls := []string{
+		"update name new",
+		"update instance_count 2",
+		"assign ip-2",
+		"release ip-1",
+		"release ip-2",
+		"update instance_count 1",
+		"update name old",
+	}
+	if !reflect.DeepEqual(lbAPI.calls, expectedCalls) {
+		t.Fatalf("expected calls %v, got %v", expectedCalls, lbAPI.calls)
+	}
+	if newState.Attributes["name"] != "old" || newState.Attributes["instance_count"] != "1" {
+		t.Fatalf("expected name old and instance_count 1 in state, got %s and %s",
+			newState.Attributes["name"], newState.Attributes["instance_count"])
+	}
+	if ipIDs := testLoadBalancerIPAddressIDs(newState); !reflect.DeepEqual(ipIDs, []string{"ip-1"}) {
+		t.Fatalf("expected ip addresses [ip-1] in state, got %v", ipIDs)
+	}
+}
+
+func TestResourceAHLoadBalancer_UpdateIPAddresses(t *testing.T) {
+	cases := []struct {
+		name          string
+		ipAddress     interface{}
+		failOn        string
+		expectedErr   string
+		expectedCalls []string
+		expectedIPs   []string
+	}{
+		{"add", []interface{}{map[string]interface{}{"id": "ip-1"}, map[string]interface{}{"id": "ip-2"}}, "", "",
+			[]string{"assign ip-2"}, []string{"ip-1", "ip-2"}},
+		{"replace", []interface{}{map[string]interface{}{"id": "ip-2"}}, "", "",
+			[]string{"assign ip-2", "release ip-1"}, []string{"ip-2"}},
+		{"release all", []interface{}{}, "", "", []string{"release ip-1"}, nil},
+		{"unset", nil, "", "", nil, []string{"ip-1"}},
+		{"failed assignment", []interface{}{map[string]interface{}{"id": "ip-2"}}, "assign ip-2", "error assigning ip address ip-2: assign ip-2 failed",
+			[]string{"assign ip-2"}, []string{"ip-1"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lbAPI := &fakeLoadBalancersAPI{
+				loadBalancer: ah.LoadBalancer{
+					ID:                 "lb-id",
+					Name:               "lb",
+					State:              "active",
+					BalancingAlgorithm: "round_robin",
+					IPAddresses:        []ah.LBIPAddress{{ID: "ip-1", Type: "public", State: "active"}},
+				},
+				failOn: tc.failOn,
+			}
+			meta := testFakeMeta()
+			meta.client.LoadBalancers = lbAPI
+
+			config := map[string]interface{}{
+				"name":           "lb",
+				"datacenter":     DatacenterName,
+				"instance_count": 1,
+			}
+			if tc.ipAddress != nil {
+				config["ip_address"] = tc.ipAddress
+			}
+			newState, diags := testLoadBalancerUpdate(t, meta, 1, config)
+			if tc.expectedErr == "" && diags.HasError() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+			if tc.expectedErr != "" && (!diags.HasError() || diags[0].Summary != tc.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, diags)
+			}
+
+			if !reflect.DeepEqual(lbAPI.calls, tc.expectedCalls) {
+				t.Fatalf("expected calls %v, got %v", tc.expectedCalls, lbAPI.calls)
+			}
+			if ipIDs := testLoadBalancerIPAddressIDs(newState); !reflect.DeepEqual(ipIDs, tc.expectedIPs) {
+				t.Fatalf("expected ip addresses %v in state, got %v", tc.expectedIPs, ipIDs)
+			}
+		})
+	}
+}
+
 func testAccCheckAHLoadBalancerDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_load_balancer" {
@@ -435,6 +749,19 @@ func testAccCheckAHLoadBalancerConfig_Empty(name string) string {
 	 }`, name, DatacenterName)
 }
 
+func testAccCheckAHLoadBalancerConfig_Cleared(name string) string {
+	return fmt.Sprintf(`
+	 resource "ah_load_balancer" "web" {
+	   name = "%s"
+	   datacenter = "%s"
+	   balancing_algorithm = "round_robin"
+	   instance_count = 1
+       create_public_ip_address = false
+       forwarding_rule = []
+       health_check = []
+	 }`, name, DatacenterName)
+}
+
 func testAccCheckAHLoadBalancerConfig_LeastRequests(name string) string {
 	return fmt.Sprintf(`
 	 resource "ah_load_balancer" "web" {
@@ -446,6 +773,54 @@ func testAccCheckAHLoadBalancerConfig_LeastRequests(name string) string {
 	 }`, name, DatacenterName)
 }
 
+func testAccCheckAHLoadBalancerConfig_ProxyProtocol(name, proxyProtocol string) string {
+	return fmt.Sprintf(`
+	 resource "ah_load_balancer" "web" {
+	   name = "%s"
+	   datacenter = "%s"
+	   balancing_algorithm = "round_robin"
+	   proxy_protocol = "%s"
+	   instance_count = 1
+       create_public_ip_address = false
+	 }`, name, DatacenterName, proxyProtocol)
+}
+
+func testAccCheckAHLoadBalancerConfig_WithIP(name string) string {
+	return fmt.Sprintf(`
+	 resource "ah_ip" "test" {
+	   type = "public"
+	   datacenter = "%s"
+	 }
+
+	 resource "ah_load_balancer" "web" {
+	   name = "%s"
+	   datacenter = "%s"
+	   balancing_algorithm = "round_robin"
+	   instance_count = 1
+       create_public_ip_address = false
+       ip_address {
+         id = ah_ip.test.id
+       }
+	 }`, DatacenterID, name, DatacenterName)
+}
+
+func testAccCheckAHLoadBalancerConfig_WithoutIP(name string) string {
+	return fmt.Sprintf(`
+	 resource "ah_ip" "test" {
+	   type = "public"
+	   datacenter = "%s"
+	 }
+
+	 resource "ah_load_balancer" "web" {
+	   name = "%s"
+	   datacenter = "%s"
+	   balancing_algorithm = "round_robin"
+	   instance_count = 1
+       create_public_ip_address = false
+       ip_address = []
+	 }`, DatacenterID, name, DatacenterName)
+}
+
 func testAccCheckAHLoadBalancerConfig_WithFR(name string) string {
 	return fmt.Sprintf(`
 	 resource "ah_load_balancer" "web" {
@@ -584,6 +959,7 @@ func testAccCheckAHLoadBalancerConfig_WithoutBackendNode(name string) string {
        private_network {
          id = ah_private_network.test.id
        }
+       backend_node = []
 	 }`, name, DatacenterName, VpsPlanID, DatacenterName)
 
 }
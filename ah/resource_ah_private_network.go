@@ -43,7 +43,7 @@ func resourceAHPrivateNetwork() *schema.Resource {
 }
 
 func resourceAHPrivateNetworkCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	request := &ah.PrivateNetworkCreateRequest{
 		CIDR: d.Get("ip_range").(string),
@@ -70,7 +70,7 @@ func resourceAHPrivateNetworkCreate(d *schema.ResourceData, meta interface{}) er
 }
 
 func resourceAHPrivateNetworkRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	privateNetwork, err := client.PrivateNetworks.Get(context.Background(), d.Id())
 	if err != nil {
 		return err
@@ -85,7 +85,7 @@ func resourceAHPrivateNetworkRead(d *schema.ResourceData, meta interface{}) erro
 }
 
 func resourceAHPrivateNetworkUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	if d.HasChange("ip_range") {
 		updateRequest := &ah.PrivateNetworkUpdateRequest{
@@ -115,7 +115,7 @@ func resourceAHPrivateNetworkUpdate(d *schema.ResourceData, meta interface{}) er
 }
 
 func resourceAHPrivateNetworkDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.PrivateNetworks.Delete(context.Background(), d.Id()); err != nil {
 		if err == ah.ErrResourceNotFound {
 			return nil
@@ -129,7 +129,7 @@ func resourceAHPrivateNetworkDelete(d *schema.ResourceData, meta interface{}) er
 }
 
 func waitForPrivateNetworkCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		privateNetwork, err := client.PrivateNetworks.Get(context.Background(), d.Id())
@@ -159,7 +159,7 @@ func waitForPrivateNetworkCreate(d *schema.ResourceData, meta interface{}) error
 }
 
 func waitForPrivateNetworkDestroy(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		privateNetwork, err := client.PrivateNetworks.Get(context.Background(), d.Id())
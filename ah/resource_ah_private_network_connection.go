@@ -38,7 +38,7 @@ func resourceAHPrivateNetworkConnection() *schema.Resource {
 }
 
 func resourceAHPrivateNetworkConnectionCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	privateNetworkID := d.Get("private_network_id").(string)
 
@@ -68,7 +68,7 @@ func resourceAHPrivateNetworkConnectionCreate(d *schema.ResourceData, meta inter
 }
 
 func resourceAHPrivateNetworkConnectionRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	instancePrivateNetwork, err := client.InstancePrivateNetworks.Get(context.Background(), d.Id())
 
@@ -83,7 +83,7 @@ func resourceAHPrivateNetworkConnectionRead(d *schema.ResourceData, meta interfa
 }
 
 func resourceAHPrivateNetworkConnectionUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	if d.HasChange("ip_address") {
 		updateRequest := &ah.InstancePrivateNetworkUpdateRequest{
@@ -103,7 +103,7 @@ func resourceAHPrivateNetworkConnectionUpdate(d *schema.ResourceData, meta inter
 }
 
 func resourceAHPrivateNetworkConnectionDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if _, err := client.InstancePrivateNetworks.Delete(context.Background(), d.Id()); err != nil {
 		if err == ah.ErrResourceNotFound {
 			return nil
@@ -117,7 +117,7 @@ func resourceAHPrivateNetworkConnectionDelete(d *schema.ResourceData, meta inter
 }
 
 func waitForInstanceConnectionToPrivateNetwork(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		instancePrivateNetwork, err := client.InstancePrivateNetworks.Get(context.Background(), d.Id())
@@ -147,7 +147,7 @@ func waitForInstanceConnectionToPrivateNetwork(d *schema.ResourceData, meta inte
 }
 
 func waitForInstancePrivateNetworkDestroy(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 
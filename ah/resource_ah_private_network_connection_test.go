@@ -110,7 +110,7 @@ func TestAccAHPrivateNetworkConnection_ChangePrivateNetwork(t *testing.T) {
 }
 
 func testAccCheckAHPrivateNetworkConnectionDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_private_network_connection" {
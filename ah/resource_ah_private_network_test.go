@@ -56,7 +56,7 @@ func TestAccAHPrivateNetwork_UpdateName(t *testing.T) {
 }
 
 func testAccCheckAHPrivateNetworkDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_private_network" {
@@ -38,7 +38,7 @@ func resourceAHSSHKey() *schema.Resource {
 }
 
 func resourceAHSSHKeyCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	publicKey := d.Get("public_key").(string)
 	_, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
@@ -61,6 +61,7 @@ func resourceAHSSHKeyCreate(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return fmt.Errorf("Error creating ssh key: %s", err)
 	}
+	meta.(*CombinedConfig).cache.sshKeys.invalidate()
 
 	d.SetId(sshKey.ID)
 
@@ -69,7 +70,7 @@ func resourceAHSSHKeyCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHSSHKeyRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	sshKey, err := client.SSHKeys.Get(context.Background(), d.Id())
 	if err != nil {
 		return err
@@ -84,7 +85,7 @@ func resourceAHSSHKeyRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHSSHKeyUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	updateRequest := &ah.SSHKeyUpdateRequest{}
 
@@ -106,10 +107,11 @@ func resourceAHSSHKeyUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHSSHKeyDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.SSHKeys.Delete(context.Background(), d.Id()); err != nil {
 		return fmt.Errorf(
 			"Error deleting ssh key (%s): %s", d.Id(), err)
 	}
+	meta.(*CombinedConfig).cache.sshKeys.invalidate()
 	return nil
 }
@@ -113,7 +113,7 @@ func TestAccAHSSHKey_UpdateName(t *testing.T) {
 }
 
 func testAccCheckAHSSHKeyDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_ssh_key" {
@@ -63,6 +63,11 @@ func resourceAHVolume() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			// monthly_cost is set by the plan so it shows the cost of new resources and plan changes
+			"monthly_cost": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
 		},
 		CustomizeDiff: customdiff.All(
 			customdiff.ValidateChange("size", func(ctx context.Context, old, new, meta interface{}) error {
@@ -71,12 +76,13 @@ func resourceAHVolume() *schema.Resource {
 				}
 				return nil
 			}),
+			volumeCostDiff,
 		),
 	}
 }
 
 func resourceAHVolumeCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	name := d.Get("name").(string)
 
 	var planAttr string
@@ -149,7 +155,7 @@ func resourceAHVolumeCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHVolumeRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	volume, err := client.Volumes.Get(context.Background(), d.Id())
 	if err != nil {
 		return err
@@ -165,7 +171,7 @@ func resourceAHVolumeRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHVolumeUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	if d.HasChange("name") {
 
@@ -196,7 +202,7 @@ func resourceAHVolumeUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAHVolumeDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	if err := client.Volumes.Delete(context.Background(), d.Id()); err != nil {
 		return fmt.Errorf(
 			"Error deleting volume (%s): %s", d.Id(), err)
@@ -209,7 +215,7 @@ func resourceAHVolumeDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func waitForVolumeState(volumeID string, pending, target []string, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		volume, err := client.Volumes.Get(context.Background(), volumeID)
@@ -240,7 +246,7 @@ func waitForVolumeState(volumeID string, pending, target []string, d *schema.Res
 }
 
 func waitForVolumeDestroy(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		volume, err := client.Volumes.Get(context.Background(), d.Id())
@@ -274,7 +280,7 @@ func waitForVolumeDestroy(d *schema.ResourceData, meta interface{}) error {
 }
 
 func waitForActionCopyReady(VolumeID, actionID string, d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	stateRefreshFunc := func() (interface{}, string, error) {
 		action, err := client.Volumes.ActionInfo(context.Background(), VolumeID, actionID)
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/advancedhosting/advancedhosting-api-go/ah"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -33,7 +32,7 @@ func resourceAHVolumeAttachment() *schema.Resource {
 }
 
 func resourceAHVolumeAttachmenCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	instanceID := d.Get("cloud_server_id").(string)
 
@@ -58,7 +57,7 @@ func resourceAHVolumeAttachmenCreate(d *schema.ResourceData, meta interface{}) e
 
 func resourceAHVolumeAttachmenRead(d *schema.ResourceData, meta interface{}) error {
 
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 	volumeID := d.Get("volume_id").(string)
 
 	volume, err := client.Volumes.Get(context.Background(), volumeID)
@@ -76,7 +75,7 @@ func resourceAHVolumeAttachmenRead(d *schema.ResourceData, meta interface{}) err
 
 func resourceAHVolumeAttachmenDelete(d *schema.ResourceData, meta interface{}) error {
 
-	client := meta.(*ah.APIClient)
+	client := meta.(*CombinedConfig).ahClient()
 
 	instanceID := d.Get("cloud_server_id").(string)
 
@@ -130,7 +130,7 @@ func testAccCheckAHVolumeAttachmentRecreated(t *testing.T, beforeID, afterID *st
 }
 
 func testAccCheckAHVolumeAttachmentDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_volume_attachment" {
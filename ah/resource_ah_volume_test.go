@@ -180,7 +180,7 @@ func TestAccAHVolume_ChangeFileSystem(t *testing.T) {
 }
 
 func testAccCheckAHVolumeDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(*ah.APIClient)
+	client := testAccProvider.Meta().(*CombinedConfig).ahClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "ah_volume" {